@@ -1,25 +1,27 @@
 package openapigen
 
 import (
+	"encoding"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"reflect"
 	"regexp"
 	"slices"
-	"strconv"
 	"strings"
-	"time"
 
 	"github.com/fmarmol/kin-openapi/openapi3"
 	"github.com/fmarmol/openapigen/utils"
-	"github.com/google/uuid"
 	"gopkg.in/yaml.v3"
 )
 
 var _enumImpl = reflect.TypeOf((*Enum)(nil)).Elem()
 var _extensionsImpl = reflect.TypeOf((*ExtensionsI)(nil)).Elem()
 var _selfExtentionsImpl = reflect.TypeOf((*SelfExtensionsI)(nil)).Elem()
+var _schemaProviderImpl = reflect.TypeOf((*SchemaProvider)(nil)).Elem()
+var _textMarshalerImpl = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+var _jsonMarshalerImpl = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
 
 var matchFirstCap = regexp.MustCompile("(.)([A-Z][a-z]+)")
 var matchAllCap = regexp.MustCompile("([a-z0-9])([A-Z])")
@@ -44,8 +46,28 @@ type Property struct {
 	nullable             bool
 	minimum              *float64
 	maximum              *float64
+	exclusiveMinimum     bool
+	exclusiveMaximum     bool
+	multipleOf           *float64
+	minLength            *uint64
+	maxLength            *uint64
+	pattern              string
+	minItems             *uint64
+	maxItems             *uint64
+	uniqueItems          bool
+	minProperties        *uint64
+	maxProperties        *uint64
+	readOnly             bool
+	writeOnly            bool
+	title                string
+	example              any
+	_const               any
 	enums                []any
 	extensions           map[string]any
+	allOf                bool             // embedded struct composed with allOf, ref to its schema
+	inline               bool             // struct described in place instead of referenced
+	properties           []Property       // properties of an inline struct
+	schema               *openapi3.Schema // schema of a registered type
 }
 
 func (p Property) String() string {
@@ -63,6 +85,9 @@ func (p Property) String() string {
 		"nullable":            p.nullable,
 		"minimum":             p.minimum,
 		"maximum":             p.maximum,
+		"exclusive_minimum":   p.exclusiveMinimum,
+		"exclusive_maximum":   p.exclusiveMaximum,
+		"_const":              p._const,
 		"enums":               p.enums,
 		"extensions":          p.extensions,
 	}
@@ -76,32 +101,57 @@ type Schema struct {
 	name   string
 	enums  []any
 	array  bool
+	inline bool
 }
 
-func (s *Parameter) RefPath() string {
+func (s *Parameter) RefPath() (string, error) {
 	if s.componentName == "" {
-		panic("anonymous struct is not supported yet")
+		return "", fmt.Errorf("parameter %q: component has no name", s.name)
 	}
-	return fmt.Sprintf("#/components/parameters/%s", s.componentName)
+	return fmt.Sprintf("#/components/parameters/%s", s.componentName), nil
 }
 
-func (s *Schema) RefPath() string {
+func (s *Schema) RefPath() (string, error) {
 	name := s.ObjectName()
 	if name == "" {
-		panic("anonymous struct is not supported yet")
+		return "", s.unnamedError()
 	}
-	return fmt.Sprintf("#/components/schemas/%s", s.ObjectName())
+	return fmt.Sprintf("#/components/schemas/%s", name), nil
 }
 
-func (s *Schema) ObjectName() string {
+// unnamedError returns the error of a schema without component name.
+func (s *Schema) unnamedError() error {
 	_type := reflect.TypeOf(s.object)
-	name := _type.Name()
-	if _type.Kind() == reflect.Slice {
-		name = _type.Elem().Name() + "s" // TODO: a better pluralize function
+	if elemType := derefType(_type); elemType != nil {
+		switch kind := elemType.Kind(); kind {
+		case reflect.Map, reflect.Slice, reflect.Array:
+			return typeError(_type, fmt.Errorf("%w: %s has no component name", ErrUnsupportedType, kind))
+		}
 	}
+	return typeError(_type, ErrAnonymousStruct)
+}
+
+func (s *Schema) ObjectName() string {
 	if s.object == nil || s.name != "" {
 		return s.name
 	}
+	if poly, ok := s.object.(*Polymorphic); ok {
+		return poly.objectName()
+	}
+	if namer, ok := s.object.(SchemaNamer); ok {
+		return namer.SchemaName()
+	}
+	_type := derefType(reflect.TypeOf(s.object))
+	name := _type.Name()
+	switch _type.Kind() {
+	case reflect.Slice:
+		if !isStructSlice(_type) {
+			return ""
+		}
+		name = derefType(_type.Elem()).Name() + "s" // TODO: a better pluralize function
+	case reflect.Array:
+		return ""
+	}
 
 	if strings.Contains(name, "[") { // we assume we met a generic type, need to transform the name in something compatible with openapi
 		name = strings.ReplaceAll(name, "[", "_")
@@ -112,138 +162,273 @@ func (s *Schema) ObjectName() string {
 	return name
 }
 
-func (s *Schema) Properties() ([]Property, []*Schema) {
+func (s *Schema) Properties() ([]Property, []*Schema, error) {
 	return Properties(s.object)
 }
 
-func tagFieldLookUp(tags []string, key string) (string, bool) {
-	for _, t := range tags {
-		t = strings.TrimSpace(t)
-		if strings.Contains(t, key+":") {
-			value := t[len(key)+1:]
-			return value, true
-		}
-	}
-	return "", false
-
-}
-
-func setProperty(property *Property, newSchemas []*Schema, _type reflect.Type) ([]*Schema, *Schema) { // (all schemas, last schema added)
+func (r *reflector) setProperty(property *Property, newSchemas []*Schema, _type reflect.Type) ([]*Schema, *Schema, error) { // (all schemas, last schema added)
 	kind := _type.Kind()
 	var lastSchema *Schema
+	var err error
+
+	if schema := r.typeSchema(_type); schema != nil {
+		property.schema = schema
+		if schema.Type != nil && len(*schema.Type) == 1 {
+			property._type = (*schema.Type)[0]
+		}
+		return newSchemas, nil, nil
+	}
+	if kind != reflect.Pointer && _type.Implements(_enumImpl) {
+		enums, err := enumValues(_type)
+		if err != nil {
+			return newSchemas, nil, err
+		}
+		newSchema := &Schema{enums: enums, object: reflect.New(_type).Elem().Interface()}
+		if property.ref, err = r.refPath(newSchema); err != nil {
+			return newSchemas, nil, err
+		}
+		return append(newSchemas, newSchema), newSchema, nil
+	}
+	// the json encoding of a marshaler is unknown, it is described as a string
+	// like encoding/json does with a TextMarshaler
+	if isMarshaler(_type) {
+		property._type = "string"
+		return newSchemas, nil, nil
+	}
 
 	switch kind {
 	case reflect.Pointer:
-		return setProperty(property, newSchemas, _type.Elem())
-	case reflect.Int8, reflect.Int16, reflect.Int, reflect.Uint8:
-		property._type = "integer"
-	case reflect.Int32:
-		property._type = "integer"
-		property.format = "int32"
-	case reflect.Int64:
-		property._type = "integer"
-		property.format = "int64"
-	case reflect.Uint64:
-		property._type = "integer"
-		property.format = "uint64"
-	case reflect.Float64:
-		property._type = "number"
-		property.format = "double"
-	case reflect.Float32:
-		property._type = "number"
-		property.format = "float"
-	case reflect.Bool:
-		property._type = "boolean"
-	case reflect.String:
-		property._type = "string"
-	case reflect.TypeOf(uuid.UUID{}).Kind():
-		property._type = "string"
-		property.format = "uuid"
+		return r.setProperty(property, newSchemas, _type.Elem())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.Bool, reflect.String:
+		scalar := kindTypes[kind]
+		property._type = scalar._type
+		if scalar.format != "" {
+			property.format = scalar.format
+		}
+	case reflect.Array:
+		length := uint64(_type.Len())
+		property.minItems, property.maxItems = &length, &length
+		property.itemsProp = &Property{inline: property.inline}
+		newSchemas, lastSchema, err = r.setProperty(property.itemsProp, newSchemas, _type.Elem())
 	case reflect.Struct:
+		// a type is inlined in itself only once, the next levels reference it
+		if (property.inline || _type.Name() == "") && !slices.Contains(r.inlining, _type) {
+			return r.inlineProperty(property, newSchemas, _type)
+		}
 		newSchema := NewSchema(reflect.New(_type).Elem().Interface())
-		property.ref = newSchema.RefPath()
+		property.ref, err = r.refPath(newSchema)
+		if err != nil {
+			return newSchemas, nil, err
+		}
 		lastSchema = newSchema
 		newSchemas = append(newSchemas, newSchema)
 	case reflect.Slice:
 		elemType := _type.Elem()
-		property.itemsProp = &Property{}
-		newSchemas, lastSchema = setProperty(property.itemsProp, newSchemas, elemType)
+		// like []byte, encoding/json writes the slices of bytes as base64 strings
+		if elemType.Kind() == reflect.Uint8 && !isMarshaler(elemType) {
+			property._type = "string"
+			if property.format == "" {
+				property.format = "byte"
+			}
+			return newSchemas, nil, nil
+		}
+		property.itemsProp = &Property{inline: property.inline}
+		newSchemas, lastSchema, err = r.setProperty(property.itemsProp, newSchemas, elemType)
 	case reflect.Map:
-		keyType := _type.Key()
-		if keyType != reflect.TypeOf("") {
-			panic("key has to be string")
+		if keyType := _type.Key(); !isMapKey(keyType) {
+			return newSchemas, nil, fmt.Errorf("%w: map key has to be a string, an integer or an encoding.TextMarshaler, got %v", ErrUnsupportedType, keyType)
 		}
-		property.additionalProperties = &Property{}
-		newSchemas, lastSchema = setProperty(property.additionalProperties, newSchemas, reflect.New(_type.Elem()).Elem().Type())
+		property.additionalProperties = &Property{inline: property.inline}
+		newSchemas, lastSchema, err = r.setProperty(property.additionalProperties, newSchemas, _type.Elem())
+	case reflect.Interface:
+		poly, ok := r.interfaces[_type]
+		// any value can be stored in the empty interface
+		if !ok && _type.NumMethod() == 0 {
+			property.schema = &openapi3.Schema{}
+			return newSchemas, nil, nil
+		}
+		if !ok {
+			return newSchemas, nil, fmt.Errorf("%w: interface %v has no registered implementations", ErrUnsupportedType, _type)
+		}
+		newSchema := NewSchema(poly)
+		property.ref, err = r.refPath(newSchema)
+		if err != nil {
+			return newSchemas, nil, err
+		}
+		lastSchema = newSchema
+		newSchemas = append(newSchemas, newSchema)
 	default:
-		panic(fmt.Errorf("kind %v not supported yet %v", _type.Kind(), property))
+		return newSchemas, nil, fmt.Errorf("%w: kind %v", ErrUnsupportedType, kind)
+	}
+	return newSchemas, lastSchema, err
+
+}
+
+// inlineProperty describes a struct in place, its fields become the
+// properties of property.
+func (r *reflector) inlineProperty(property *Property, newSchemas []*Schema, _type reflect.Type) ([]*Schema, *Schema, error) {
+	r.inlining = append(r.inlining, _type)
+	defer func() { r.inlining = r.inlining[:len(r.inlining)-1] }()
+
+	fields, fieldSchemas, err := r.structFields(_type)
+	property._type = "object"
+	property.properties = []Property{}
+	for _, field := range dominantFields(fields) {
+		property.properties = append(property.properties, field.Property)
+	}
+	return append(newSchemas, fieldSchemas...), nil, err
+}
+
+// derefType returns the type pointed to by a pointer type.
+func derefType(_type reflect.Type) reflect.Type {
+	for _type != nil && _type.Kind() == reflect.Pointer {
+		_type = _type.Elem()
+	}
+	return _type
+}
+
+// isMarshaler reports whether a type has its own json encoding.
+func isMarshaler(_type reflect.Type) bool {
+	if kind := _type.Kind(); kind == reflect.Pointer || kind == reflect.Interface {
+		return false
+	}
+	for _, impl := range []reflect.Type{_textMarshalerImpl, _jsonMarshalerImpl} {
+		if _type.Implements(impl) || reflect.PointerTo(_type).Implements(impl) {
+			return true
+		}
+	}
+	return false
+}
+
+// isMapKey reports whether encoding/json accepts a type as map key.
+func isMapKey(_type reflect.Type) bool {
+	switch _type.Kind() {
+	case reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return _type.Implements(_textMarshalerImpl) || reflect.PointerTo(_type).Implements(_textMarshalerImpl)
+}
+
+// enumValues calls the Values method of a type implementing Enum.
+func enumValues(_type reflect.Type) ([]any, error) {
+	method, ok := _type.MethodByName("Values")
+	if !ok {
+		return nil, errors.New("not an enum")
+	}
+	values := method.Func.Call([]reflect.Value{reflect.New(_type).Elem()})
+	if len(values) != 1 {
+		return nil, errors.New("Values() method should return 1 slice")
+	}
+	enums, ok := values[0].Interface().([]any)
+	if !ok {
+		return nil, errors.New("enum values cannot be converted into []any")
 	}
-	return newSchemas, lastSchema
+	return enums, nil
+}
+
+// reflector turns go types into properties and schemas following the
+// options of a document.
+type reflector struct {
+	naming      NamingPolicy
+	interfaces  map[reflect.Type]*Polymorphic
+	collisions  NameCollisionStrategy
+	components  map[string]any                 // component name -> schema key
+	schemaNames map[any]string                 // schema key -> component name
+	schemas     map[string]*openapi3.SchemaRef // registered components, nil while being registered
+	inlining    []reflect.Type                 // structs being described in place
+	validation  []string                       // tags of the validator rules
+	types       map[reflect.Type]SchemaFunc
+	comments    *docComments
+}
 
+// Properties reflects the properties of a struct with the default options.
+func Properties(object any) ([]Property, []*Schema, error) {
+	return (&reflector{}).properties(object)
 }
 
-func Properties(object any) ([]Property, []*Schema) {
+func (r *reflector) properties(object any) ([]Property, []*Schema, error) {
 	ret := []Property{}
 	newSchemas := []*Schema{}
 
 	_type := reflect.TypeOf(object)
+	if _type == nil {
+		return nil, nil, typeError(_type, errors.New("object is nil"))
+	}
 
 	if _type.Kind() == reflect.Pointer {
 		_type = _type.Elem()
 	}
 
 	if _type.Kind() == reflect.Slice {
-		elemType := _type.Elem()
+		elemType := derefType(_type.Elem())
 		if elemType.Kind() == reflect.Struct {
-			newSchema := NewSchema(reflect.New(_type.Elem()).Elem().Interface())
+			newSchema := NewSchema(reflect.New(elemType).Elem().Interface())
 			newSchema.array = true
 			newSchema.owner = NewSchema(object)
 			newSchemas = append(newSchemas, newSchema)
 		}
-		return ret, newSchemas
+		return ret, newSchemas, nil
 	}
 
 	if _type.Kind() != reflect.Struct {
-		panic(fmt.Errorf("object %v is not a struct", _type.Name()))
+		return nil, nil, typeError(_type, errors.New("object is not a struct"))
 	}
 
+	// Enum in parameter
+	if _type.Implements(_enumImpl) {
+		enums, err := enumValues(_type)
+		if err != nil {
+			return nil, nil, typeError(_type, err)
+		}
+		newSchema := &Schema{enums: enums, object: reflect.New(_type).Elem().Interface()}
+		newSchemas = append(newSchemas, newSchema)
+	}
+
+	fields, fieldSchemas, err := r.structFields(_type)
+	for _, field := range dominantFields(fields) {
+		ret = append(ret, field.Property)
+	}
+	return ret, append(newSchemas, fieldSchemas...), err
+}
+
+// structField is a property found in a struct, with what is needed to
+// resolve conflicts between promoted fields the way encoding/json does.
+type structField struct {
+	Property
+	depth  int
+	tagged bool
+}
+
+// structFields returns the properties of a struct, including the ones promoted
+// from embedded structs. parents are the structs embedding _type, a struct
+// embedding itself is not flattened again.
+func (r *reflector) structFields(_type reflect.Type, parents ...reflect.Type) ([]structField, []*Schema, error) {
+	var ret []structField
+	newSchemas := []*Schema{}
+
 	// Extensions
 	var extensions map[FieldName]Extensions
 	if _type.Implements(_extensionsImpl) {
 		method, ok := _type.MethodByName("Extensions")
 		if !ok {
-			panic("not an extension")
+			return nil, nil, typeError(_type, errors.New("not an extension"))
 		}
-		dst := reflect.New(_type).Elem()
-		values := method.Func.Call([]reflect.Value{dst})
+		values := method.Func.Call([]reflect.Value{reflect.New(_type).Elem()})
 		if len(values) != 1 {
-			panic("Values() method should return a map, 0 found")
+			return nil, nil, typeError(_type, errors.New("Extensions() method should return a map"))
 		}
 		_extensions, ok := values[0].Interface().(map[string]map[string]any)
 		if !ok {
-			panic("extensions type cannot be converted into map[string]map[string]any")
+			return nil, nil, typeError(_type, errors.New("extensions type cannot be converted into map[string]map[string]any"))
 		}
 		extensions = _extensions
 	}
 
-	// Enum in parameter
-	if _type.Implements(_enumImpl) {
-		method, ok := _type.MethodByName("Values")
-		if !ok {
-			panic("not an enum")
-		}
-		dst := reflect.New(_type).Elem()
-		values := method.Func.Call([]reflect.Value{dst})
-		if len(values) != 1 {
-			panic("Values() method should return 1 slice, 0 found")
-		}
-		enums, ok := values[0].Interface().([]any)
-		if !ok {
-			panic("enum values cannot be converted into []any")
-		}
-		newSchema := &Schema{enums: enums, object: dst.Interface()}
-		newSchemas = append(newSchemas, newSchema)
-	}
+	var errs []error
 
 	for i := range _type.NumField() {
 		field := _type.Field(i)
@@ -251,111 +436,187 @@ func Properties(object any) ([]Property, []*Schema) {
 			field.Type = reflect.New(field.Type.Elem()).Elem().Type()
 		}
 
-		fieldName := ToSnakeCase(field.Name)
-		if !field.IsExported() {
+		rawTag := field.Tag.Get("oapi")
+		if rawTag == "-" {
 			continue
 		}
-		var property Property
-
-		tag := field.Tag.Get("oapi")
-		if tag == "-" {
+		tag, err := parseTag(rawTag)
+		if err != nil {
+			errs = append(errs, fieldError(_type, field.Name, fmt.Errorf("oapi tag: %w", err)))
 			continue
 		}
-		if tag == "" {
-			property.name = fieldName
-
-		} else {
-			tagValues := strings.Split(tag, ",")
-			if len(tagValues) == 0 {
-				panic("invalid oapi tag")
-			}
-			if value, ok := tagFieldLookUp(tagValues, "name"); ok {
-				property.name = value
-			} else {
-				property.name = fieldName
-			}
-			if value, ok := tagFieldLookUp(tagValues, "format"); ok {
-				property.format = value
-			}
-			if value, ok := tagFieldLookUp(tagValues, "description"); ok {
-				property.description = value
-			}
-			if _, ok := tagFieldLookUp(tagValues, "deprecated"); ok {
-				property.deprecated = true
-			}
-			if value, ok := tagFieldLookUp(tagValues, "default"); ok {
-				property._default = parseString(value)
-			}
-			if value, ok := tagFieldLookUp(tagValues, "min"); ok {
-				if val, err := strconv.ParseFloat(value, 64); err == nil {
-					property.minimum = &val
-				}
+		jsonField := r.naming.field(field)
+		if jsonField.skip {
+			continue
+		}
+		oapiNamed := tag.has("name")
+		named := jsonField.named || oapiNamed
+
+		// embedded structs are flattened into their parent like encoding/json
+		// does, unless they are given a name or composed with allOf
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && !named {
+			if field.Type == _type || slices.Contains(parents, field.Type) {
+				continue
 			}
-			if value, ok := tagFieldLookUp(tagValues, "max"); ok {
-				if val, err := strconv.ParseFloat(value, 64); err == nil {
-					property.maximum = &val
+			if tag.flag("allOf") {
+				newSchema := NewSchema(reflect.New(field.Type).Elem().Interface())
+				ref, err := r.refPath(newSchema)
+				if err != nil {
+					errs = append(errs, fieldError(_type, field.Name, err))
+					continue
 				}
+				ret = append(ret, structField{Property: Property{name: field.Name, ref: ref, allOf: true}})
+				newSchemas = append(newSchemas, newSchema)
+				continue
 			}
-			if slices.Contains(tagValues, "required:true") {
-				property.required = true
+			embedded, embeddedSchemas, err := r.structFields(field.Type, append(parents, _type)...)
+			if err != nil {
+				errs = append(errs, err)
 			}
-			if slices.Contains(tagValues, "nullable:true") {
-				property.nullable = true
+			for _, f := range embedded {
+				f.depth++
+				ret = append(ret, f)
 			}
+			newSchemas = append(newSchemas, embeddedSchemas...)
+			continue
 		}
-		exts, ok := extensions[field.Name]
-		if ok {
-			property.extensions = exts
+
+		if !field.IsExported() {
+			continue
 		}
+		var property Property
+		property.name = jsonField.name
+		property.required = r.naming.required(jsonField)
+		property.description = r.comments.fieldDoc(_type, field.Name)
 
-		if field.Type.Implements(_enumImpl) {
-			method, ok := field.Type.MethodByName("Values")
-			if !ok {
-				panic("not an enum")
-			}
-			dst := reflect.New(field.Type).Elem()
-			values := method.Func.Call([]reflect.Value{dst})
-			if len(values) != 1 {
-				panic("Values() method should return 1 slice, 0 found")
-			}
-			enums, ok := values[0].Interface().([]any)
-			if !ok {
-				panic("enum values cannot be converted into []any")
-			}
-			newSchema := &Schema{enums: enums, object: dst.Interface()}
-			property.ref = newSchema.RefPath()
-			ret = append(ret, property)
-			newSchemas = append(newSchemas, newSchema)
+		if exts, ok := extensions[field.Name]; ok {
+			property.extensions = exts
+		}
+		newSchemas, err = r.fieldProperty(&property, newSchemas, field, tag)
+		if err != nil {
+			errs = append(errs, fieldError(_type, field.Name, err))
 			continue
 		}
+		ret = append(ret, structField{Property: property, tagged: named})
 
-		switch field.Type {
-		case reflect.TypeOf(uuid.UUID{}):
-			property._type = "string"
-			property.format = "uuid"
-		case reflect.TypeOf(time.Time{}):
-			property._type = "string"
-			property.format = "date-time"
-		default:
-			newSchemas, _ = setProperty(&property, newSchemas, field.Type)
+	}
+	return ret, newSchemas, errors.Join(errs...)
+}
+
+// fieldProperty describes a struct field with its type, its oapi tag and its
+// validator rules, on top of what property already holds.
+func (r *reflector) fieldProperty(property *Property, newSchemas []*Schema, field reflect.StructField, tag oapiTag) ([]*Schema, error) {
+	if value, ok := tag.lookup("name"); ok {
+		property.name = value
+	}
+	if value, ok := tag.lookup("format"); ok {
+		property.format = value
+	}
+	if value, ok := tag.lookup("description"); ok {
+		property.description = value
+	}
+	if value, ok := tag.lookup("default"); ok {
+		property._default = parseString(value)
+	}
+	if value, ok := tag.lookup("title"); ok {
+		property.title = value
+	}
+	if value, ok := tag.lookup("const"); ok {
+		property._const = parseString(value)
+	}
+	if tag.has("required") {
+		property.required = tag.flag("required")
+	}
+	property.deprecated = tag.flag("deprecated")
+	property.nullable = tag.flag("nullable")
+	property.inline = tag.flag("inline")
+	if err := setKeywords(property, tag); err != nil {
+		return newSchemas, err
+	}
+
+	newSchemas, _, err := r.setProperty(property, newSchemas, field.Type)
+	if err != nil {
+		return newSchemas, err
+	}
+	applyValidation(property, r.validationRules(field), tag)
+	return newSchemas, checkKeywords(property, tag)
+}
+
+// dominantFields keeps, for each property name, the field encoding/json would
+// serialize: the shallowest one, or the only tagged one at that depth. Names
+// with several candidates left are dropped.
+func dominantFields(fields []structField) []structField {
+	byName := make(map[string][]structField)
+	for _, field := range fields {
+		if !field.allOf {
+			byName[field.name] = append(byName[field.name], field)
+		}
+	}
+
+	var ret []structField
+	for _, field := range fields {
+		if field.allOf {
+			ret = append(ret, field)
+			continue
+		}
+		candidates := byName[field.name]
+		if len(candidates) == 0 {
+			continue // already resolved
 		}
-		ret = append(ret, property)
+		delete(byName, field.name)
 
+		depth := slices.MinFunc(candidates, func(a, b structField) int { return a.depth - b.depth }).depth
+		candidates = utils.Filter(candidates, func(f structField) bool { return f.depth == depth })
+		if len(candidates) > 1 {
+			candidates = utils.Filter(candidates, func(f structField) bool { return f.tagged })
+		}
+		if len(candidates) == 1 {
+			ret = append(ret, candidates[0])
+		}
 	}
-	return ret, newSchemas
+	return ret
 }
 
 func NewSchema(ref any) *Schema {
 	return &Schema{object: ref}
 }
 
+// inlined reports whether the schema is described in place, anonymous structs,
+// arrays, slices of other items than named structs and types of a scalar or
+// map kind which are not enums, like string, have no component to reference.
+func (s *Schema) inlined() bool {
+	_type := reflect.TypeOf(s.object)
+	if _type == nil {
+		return false
+	}
+	if _type.Kind() == reflect.Pointer {
+		_type = _type.Elem()
+	}
+	if s.inline {
+		return true
+	}
+	switch _type.Kind() {
+	case reflect.Struct:
+		return _type.Name() == ""
+	case reflect.Slice:
+		return !isStructSlice(_type)
+	}
+	return s.enums == nil && !_type.Implements(_enumImpl)
+}
+
+// isStructSlice reports whether the items of a slice are named structs, the
+// slice is then a component referencing them.
+func isStructSlice(_type reflect.Type) bool {
+	elemType := derefType(_type.Elem())
+	return elemType.Kind() == reflect.Struct && elemType.Name() != ""
+}
+
 type Response struct {
-	code        int // -1 for default
-	description string
-	ref         *Schema
-	content     string
-	inline      []byte // WARNING: this only a temp fix to have a custom response inline, openapi3.Response (only json) (not a ref)
-	headers     map[string]*Property
+	code          int // -1 for default
+	description   string
+	contents      contents
+	headers       map[string]*ResponseHeader
+	componentName string
 }
 
 func NewResponse(code int) *Response {
@@ -364,44 +625,59 @@ func NewResponse(code int) *Response {
 	return r
 }
 
-// Header support only native types
-// TODO find a better way to set header
+// Header describes a header of the response with the type of obj.
 func (r *Response) Header(key string, obj any, description ...string) *Response {
-
-	property := new(Property)
-	newSchemas := []*Schema{}
-	_, _ = setProperty(property, newSchemas, reflect.TypeOf(obj))
-
+	h := NewResponseHeader(obj)
 	if len(description) > 0 {
-		property.description = description[0]
+		h.Description(description[0])
 	}
+	return r.AddHeader(key, h)
+}
 
+// AddHeader adds a header declared with NewResponseHeader, like a header
+// component shared by several responses.
+func (r *Response) AddHeader(key string, h *ResponseHeader) *Response {
 	if r.headers == nil {
-		r.headers = make(map[string]*Property)
+		r.headers = make(map[string]*ResponseHeader)
 	}
-	r.headers[key] = property
+	r.headers[key] = h
 	return r
 }
 
-func (r *Response) Inline(data map[string]any) *Response {
-	raw, err := json.Marshal(data)
-	if err != nil {
-		panic(err)
-	}
-	r.inline = raw
+// Example adds a named example to the content of the response declared last.
+func (r *Response) Example(name string, e *Example) *Response {
+	r.contents.example(name, e)
 	return r
 }
 
+// AsComponent declares the response in the components of the document, the
+// operations reference it instead of repeating it.
+func (r *Response) AsComponent(name string) *Response {
+	r.componentName = name
+	return r
+}
+
+// Content adds a content type to the response, described by the type of
+// obj. A response can have several content types.
 func (r *Response) Content(s string, obj any) *Response {
-	r.content = s
-	r.ref = NewSchema(obj)
+	return r.MediaType(s, NewMediaType(obj))
+}
+
+// MediaType adds a content type to the response, with its examples and its
+// encoding.
+func (r *Response) MediaType(s string, m *MediaType) *Response {
+	r.contents.set(s, m)
 	return r
 }
 
 func (r *Response) JSON(object any) *Response {
-	r.content = "application/json"
-	r.ref = NewSchema(object)
-	return r
+	return r.Content("application/json", object)
+}
+
+// JSONInline describes the object in the response instead of referencing the
+// component of its type.
+func (r *Response) JSONInline(object any) *Response {
+	return r.MediaType("application/json", NewMediaType(object).Inline())
 }
 
 func (r *Response) Description(s string) *Response {
@@ -415,14 +691,39 @@ type Tag struct {
 }
 
 type Document struct {
-	t               *openapi3.T
-	paths           []*Path
-	Version         string
-	Title           string
-	servers         []string
-	bearerAuth      bool // only support bearer JWT for now
-	tags            []Tag
-	defaultResponse *Response
+	t                    *openapi3.T
+	paths                []*Path
+	Version              string
+	Title                string
+	servers              []string
+	securitySchemes      map[string]*SecurityScheme
+	security             openapi3.SecurityRequirements
+	tags                 []Tag
+	defaultResponse      *Response
+	naming               NamingPolicy
+	interfaces           map[reflect.Type]*Polymorphic
+	collisions           NameCollisionStrategy
+	validationTags       []string
+	types                map[reflect.Type]SchemaFunc
+	comments             *docComments
+	openapiVersion       string
+	jsonSchemaDialect    string
+	webhooks             []webhook
+	strictPathParameters bool
+	errs                 []error // errors found while declaring the document
+}
+
+// NameCollisions sets how a go type is named when its name is already used by
+// another type, by default the collision is reported as an error.
+func (d *Document) NameCollisions(strategy NameCollisionStrategy) *Document {
+	d.collisions = strategy
+	return d
+}
+
+// Naming sets how the fields of go structs are turned into properties.
+func (d *Document) Naming(policy NamingPolicy) *Document {
+	d.naming = policy
+	return d
 }
 
 func (d *Document) SetDefaultResponse(r *Response) *Document {
@@ -435,11 +736,6 @@ func (d *Document) Tags(tags ...Tag) *Document {
 	return d
 }
 
-func (d *Document) BearerAuth() *Document {
-	d.bearerAuth = true
-	return d
-}
-
 func (d *Document) Path(p *Path) *Document {
 	p.defaultResponse = d.defaultResponse
 	d.paths = append(d.paths, p)
@@ -483,124 +779,145 @@ func setPathItemOperation(method string, pi *openapi3.PathItem, op *openapi3.Ope
 }
 
 func (d *Document) Write(w io.Writer, indent int) error {
-	err := d.Build()
+	node, err := d.node()
 	if err != nil {
 		return err
 	}
-	finalDoc := NewYamlDocument(d)
 	enc := yaml.NewEncoder(w)
 	enc.SetIndent(indent)
-	return enc.Encode(finalDoc)
+	return enc.Encode(node)
 }
 
+// Build generates the openapi document. Problems found in the paths, their
+// parameters and the Go types they reference do not stop the build, they are
+// all returned together as a single error.
+// The document is generated from scratch on every call, so building it
+// several times gives the same result.
 func (d *Document) Build() error {
-
-	if d.t == nil {
-		servers := utils.Map(d.servers, func(s string) *openapi3.Server {
-			return &openapi3.Server{URL: s}
-		})
-		d.t = &openapi3.T{
-			OpenAPI:    "3.0.0",
-			Info:       &openapi3.Info{Version: d.Version, Title: d.Title},
-			Servers:    openapi3.Servers(servers),
-			Components: &openapi3.Components{},
-		}
-		if d.bearerAuth {
-			d.t.Security = []openapi3.SecurityRequirement{
-				map[string][]string{"bearerAuth": {}},
-			}
-			d.t.Components.SecuritySchemes = map[string]*openapi3.SecuritySchemeRef{
-				"bearerAuth": {
-					Value: &openapi3.SecurityScheme{
-						Type:         "http",
-						Scheme:       "bearer",
-						BearerFormat: "JWT",
-					},
-				}}
-		}
-
+	errs := slices.Clone(d.errs)
+	errs = append(errs, splitErrors(d.checkVersion())...)
+
+	servers := utils.Map(d.servers, func(s string) *openapi3.Server {
+		return &openapi3.Server{URL: s}
+	})
+	d.t = &openapi3.T{
+		OpenAPI:    d.version(),
+		Info:       &openapi3.Info{Version: d.Version, Title: d.Title},
+		Servers:    openapi3.Servers(servers),
+		Components: &openapi3.Components{},
+		Paths:      openapi3.NewPaths(),
+	}
+	if len(d.securitySchemes) > 0 {
+		schemes, err := d.buildSecuritySchemes()
+		errs = append(errs, splitErrors(err)...)
+		d.t.Components.SecuritySchemes = schemes
+	}
+	if len(d.security) > 0 {
+		errs = append(errs, splitErrors(d.checkSecurity(d.security))...)
+		d.t.Security = d.security
 	}
 	for _, t := range d.tags {
 		d.t.Tags = append(d.t.Tags, &openapi3.Tag{Name: t.Name, Description: t.Description})
 	}
-	if d.t.Paths == nil {
-		d.t.Paths = openapi3.NewPaths()
-	}
 
 	type OperationToRegister struct {
 		method    string
 		operation *openapi3.Operation
 	}
 
+	var pathsToRegister []string // in declaration order
 	operationsToRegister := map[string][]OperationToRegister{}
 
+	refl := &reflector{naming: d.naming, interfaces: d.interfaces, collisions: d.collisions, validation: d.validationTags, types: d.types, comments: d.comments}
 	for _, path := range d.paths {
-		path.SetDefaultResponse() // TODO try to find a better place to set
-		responses := openapi3.NewResponses()
-
-		if d.t.Components.Schemas == nil {
-			d.t.Components.Schemas = make(openapi3.Schemas)
+		operation, err := d.operation(refl, path)
+		errs = append(errs, splitErrors(err)...)
+		template, err := parsePathTemplate(path.path)
+		if err != nil {
+			errs = append(errs, operationError(path.path, path.method, err))
+			template = pathTemplate{path: path.path}
+		} else {
+			for _, err := range splitErrors(d.pathParameters(template, operation)) {
+				errs = append(errs, operationError(path.path, path.method, err))
+			}
 		}
-		for code, r := range path.apiResponses {
-			responses.Set(code, r)
+		if _, ok := operationsToRegister[template.path]; !ok {
+			pathsToRegister = append(pathsToRegister, template.path)
 		}
-		for name, schema := range path.apiSchemas {
-			d.t.Components.Schemas[name] = schema
+		operationsToRegister[template.path] = append(operationsToRegister[template.path], OperationToRegister{method: path.method, operation: operation})
+	}
+	webhooks, err := d.buildWebhooks(refl)
+	errs = append(errs, splitErrors(err)...)
+	for _, path := range pathsToRegister {
+		operations := operationsToRegister[path]
+		newPathItem := new(openapi3.PathItem)
+		for _, operation := range operations {
+			if err := setPathItemOperation(operation.method, newPathItem, operation.operation); err != nil {
+				errs = append(errs, operationError(path, operation.method, err))
+			}
 		}
 
-		if d.t.Components.Parameters == nil {
-			d.t.Components.Parameters = make(openapi3.ParametersMap)
-		}
-		for name, param := range path.componentParameters {
-			d.t.Components.Parameters[name] = param
-		}
+		d.t.Paths.Set(path, newPathItem)
+	}
 
-		operation := &openapi3.Operation{
-			Tags:        path.tags,
-			Summary:     path.summary,
-			Description: path.description,
-			OperationID: path.operationID,
-			Responses:   responses,
-			Parameters:  path.parameters,
-		}
-		if path.description == "" {
-			operation.Description = path.summary
+	if d.is31() {
+		upgradeSchemas(d.t, webhooks)
+		// openapi3.T models 3.0 documents and has no field for the root
+		// keywords added by 3.1, its extensions are written at the root of the
+		// document
+		if len(webhooks) > 0 {
+			d.t.Extensions = map[string]any{"webhooks": webhooks}
 		}
-		if path.inline != nil {
-			var openapiReq openapi3.RequestBody
-			err := json.Unmarshal(path.inline, &openapiReq)
-			if err != nil {
-				panic(err)
-			}
-			operation.RequestBody = &openapi3.RequestBodyRef{
-				Value: &openapiReq,
-			}
-		}
-		if path.ref != nil {
-			operation.RequestBody = &openapi3.RequestBodyRef{
-				Value: &openapi3.RequestBody{
-					Required: path.contentRequired,
-					Content: openapi3.Content{
-						path.content: &openapi3.MediaType{
-							Schema: &openapi3.SchemaRef{
-								Ref: path.ref.RefPath(),
-							},
-						},
-					},
-				},
+		if d.jsonSchemaDialect != "" {
+			if d.t.Extensions == nil {
+				d.t.Extensions = make(map[string]any)
 			}
+			d.t.Extensions["jsonSchemaDialect"] = d.jsonSchemaDialect
 		}
+	}
+	return errors.Join(errs...)
+}
 
-		operationsToRegister[path.path] = append(operationsToRegister[path.path], OperationToRegister{method: path.method, operation: operation})
+// operation builds the operation of a path and registers the components it
+// references.
+func (d *Document) operation(refl *reflector, path *Path) (*openapi3.Operation, error) {
+	var errs []error
+	for _, err := range splitErrors(path.build(refl)) {
+		errs = append(errs, operationError(path.path, path.method, err))
+	}
+	responses := openapi3.NewResponses()
 
+	if d.t.Components.Schemas == nil {
+		d.t.Components.Schemas = make(openapi3.Schemas)
+	}
+	for code, r := range path.apiResponses {
+		responses.Set(code, r)
+	}
+	for name, schema := range path.apiSchemas {
+		d.t.Components.Schemas[name] = schema
 	}
-	for path, operations := range operationsToRegister {
-		newPathItem := new(openapi3.PathItem)
-		for _, operation := range operations {
-			setPathItemOperation(operation.method, newPathItem, operation.operation)
-		}
 
-		d.t.Paths.Set(path, newPathItem)
+	for _, err := range d.registerComponents(path.components) {
+		errs = append(errs, operationError(path.path, path.method, err))
 	}
-	return nil
+
+	operation := &openapi3.Operation{
+		Tags:        path.tags,
+		Summary:     path.summary,
+		Description: path.description,
+		OperationID: path.operationID,
+		Responses:   responses,
+		Parameters:  path.parameters,
+		Security:    path.security,
+	}
+	if path.security != nil {
+		for _, err := range splitErrors(d.checkSecurity(*path.security)) {
+			errs = append(errs, operationError(path.path, path.method, err))
+		}
+	}
+	if path.description == "" {
+		operation.Description = path.summary
+	}
+	operation.RequestBody = path.requestBody
+	return operation, errors.Join(errs...)
 }
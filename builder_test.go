@@ -3,10 +3,13 @@ package openapigen
 import (
 	"bytes"
 	"fmt"
+	"net/http"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/fmarmol/kin-openapi/openapi3"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -17,7 +20,6 @@ type MyEnum struct{}
 func (p MyEnum) Values() []any {
 	return []any{"FOO", "BAR"}
 }
-func (p MyEnum) Kind() string { return "string" } // TODO not used yet
 
 type MyBody struct {
 	MyList []string
@@ -101,8 +103,8 @@ func TestBuilder(t *testing.T) {
 	doc.Tags(Tag{Name: "one", Description: "one des"}, Tag{Name: "two", Description: "two"})
 	doc.Server("/api").Server("/api/v3").BearerAuth().
 		Paths(
-			NewPath("/batches/").Delete().OperationID("listBatches").Summary("delete a batch").
-				Parameter(NewParameter("toto").InPath().Type("number").Min(1).Max(10)).
+			NewPath("/batches/{toto}").Delete().OperationID("listBatches").Summary("delete a batch").
+				Parameter(NewParameter("toto").InPath().Type("number").Min(1).Max(10).Required()).
 				JSONBody(Person{}).
 				// Content(Person{}, "image/*", true).
 				// Inline(map[string]any{
@@ -174,7 +176,8 @@ func TestBuilderParameter(t *testing.T) {
 		Paths(
 			NewPath("/items").Get().
 				Parameter(OrderByQueryParam).
-				Parameter(NewParameter("order_2").InQuery().Ref([]OrderBy{})),
+				Parameter(NewParameter("order_2").InQuery().Ref([]OrderBy{})).
+				Parameter(NewParameter("status").InQuery().Ref(MyEnum{})),
 		)
 
 	buffer := bytes.NewBuffer(nil)
@@ -183,3 +186,420 @@ func TestBuilderParameter(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, testBuilderParameterExpectedSpecs, strings.ReplaceAll(buffer.String(), "\n", ""))
 }
+
+type InvalidBody struct {
+	Events chan int
+	Counts map[float64]string
+}
+
+func TestBuilderDefaultResponse(t *testing.T) {
+
+	path := NewPath("/persons").Get().Responses(NewResponse(200).JSON(Person{}).Description("ok"))
+	path.SetDefaultResponse() // not built yet
+
+	doc := (&Document{}).SetDefaultResponse(NewResponse(-1).Description("unexpected error")).Path(path)
+	require.NoError(t, doc.Build())
+	path.SetDefaultResponse()
+
+	response := doc.t.Paths.Value("/persons").Get.Responses.Value("default")
+	require.NotNil(t, response)
+	assert.Equal(t, "unexpected error", *response.Value.Description)
+}
+
+func TestBuilderPointers(t *testing.T) {
+
+	doc := (&Document{}).Paths(
+		NewPath("/movies/{id}").Get().Responses(NewResponse(200).JSON(&Movie{}).Description("ok")),
+		NewPath("/movies").Get().Responses(NewResponse(200).JSON([]*Movie{}).Description("ok")),
+		NewPath("/catalog").Get().Responses(NewResponse(200).JSON(map[string]Movie{}).Description("ok")),
+	)
+	require.NoError(t, doc.Build())
+
+	schemas := doc.t.Components.Schemas
+	require.Contains(t, schemas, "Movies")
+	assert.Equal(t, "#/components/schemas/Movie", schemas["Movies"].Value.Items.Ref)
+
+	schema := func(path string) *openapi3.SchemaRef {
+		return doc.t.Paths.Value(path).Get.Responses.Value("200").Value.Content.Get("application/json").Schema
+	}
+	assert.Equal(t, "#/components/schemas/Movie", schema("/movies/{id}").Ref)
+	assert.Equal(t, "#/components/schemas/Movies", schema("/movies").Ref)
+	assert.Equal(t, "#/components/schemas/Movie", schema("/catalog").Value.AdditionalProperties.Schema.Ref)
+
+	_, err := NewSchema(map[string]Movie{}).RefPath()
+	require.ErrorIs(t, err, ErrUnsupportedType)
+	assert.EqualError(t, err, "type map[string]openapigen.Movie: type not supported: map has no component name")
+}
+
+func TestBuilderErrors(t *testing.T) {
+
+	doc := &Document{}
+	doc.
+		Paths(
+			NewPath("/invalid").Post().
+				JSONBody(InvalidBody{}),
+		)
+
+	err := doc.Write(bytes.NewBuffer(nil), 2)
+	require.Error(t, err)
+	require.ErrorIs(t, err, ErrUnsupportedType)
+
+	errs := splitErrors(err)
+	require.Len(t, errs, 2)
+	var buildErr *BuildError
+	require.ErrorAs(t, errs[0], &buildErr)
+	assert.Equal(t, "/invalid", buildErr.Path)
+	assert.Equal(t, "post", buildErr.Method)
+	assert.Equal(t, "openapigen.InvalidBody", buildErr.Type)
+	assert.Equal(t, "Events", buildErr.Field)
+	assert.Contains(t, err.Error(), "field Counts")
+}
+
+type Movie struct {
+	Title      string   `json:"title"`
+	Year       int      `json:"year,omitempty"`
+	Secret     string   `json:"-"`
+	ReleasedAt string   `json:",omitempty"`
+	DirectorID string   // no json tag
+	Rating     *float64 `json:"rating" oapi:"name:score,required:false"`
+}
+
+func TestBuilderNaming(t *testing.T) {
+
+	testCases := []struct {
+		policy   NamingPolicy
+		expected []string
+		required []string
+	}{
+		{
+			policy:   NamingPolicy{},
+			expected: []string{"title", "year", "released_at", "director_id", "score"},
+		},
+		{
+			policy:   NamingPolicy{Fallback: CamelCase, RequiredWithoutOmitEmpty: true},
+			expected: []string{"title", "year", "releasedAt", "directorId", "score"},
+			required: []string{"title"},
+		},
+		{
+			policy:   NamingPolicy{Fallback: GoName, IgnoreJSONTags: true},
+			expected: []string{"Title", "Year", "Secret", "ReleasedAt", "DirectorID", "score"},
+		},
+	}
+	for _, testCase := range testCases {
+		properties, _, err := (&reflector{naming: testCase.policy}).properties(Movie{})
+		require.NoError(t, err)
+
+		var names, required []string
+		for _, property := range properties {
+			names = append(names, property.name)
+			if property.required {
+				required = append(required, property.name)
+			}
+		}
+		assert.Equal(t, testCase.expected, names)
+		assert.Equal(t, testCase.required, required)
+	}
+}
+
+type Audit struct {
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at,omitempty"`
+}
+
+type Identity struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type Labels struct {
+	Label string `json:"label"`
+	Name  string // hidden by the tagged Identity.Name
+}
+
+type Account struct {
+	Audit `oapi:"allOf"`
+	Identity
+	*Labels
+	ID    string   `json:"id"`
+	Owner Identity `json:"owner"`
+}
+
+func TestBuilderEmbedded(t *testing.T) {
+
+	doc := &Document{}
+	doc.
+		Paths(
+			NewPath("/accounts").Get().
+				Responses(NewResponse(200).JSON(Account{}).Description("ok")),
+		)
+
+	buffer := bytes.NewBuffer(nil)
+	err := doc.Write(buffer, 2)
+	fmt.Println(buffer.String())
+	require.NoError(t, err)
+	assert.Equal(t, testBuilderEmbeddedExpectedSpecs, strings.ReplaceAll(buffer.String(), "\n", ""))
+}
+
+type Animal interface {
+	Sound() string
+}
+
+type Cat struct {
+	Kind  string `json:"kind"`
+	Lives int    `json:"lives"`
+}
+
+func (Cat) Sound() string { return "meow" }
+
+type Dog struct {
+	Kind string `json:"kind"`
+	Good bool   `json:"good"`
+}
+
+func (Dog) Sound() string { return "woof" }
+
+type Zoo struct {
+	Animals []Animal `json:"animals"`
+}
+
+func TestBuilderPolymorphic(t *testing.T) {
+
+	doc := &Document{}
+	doc.RegisterInterface((*Animal)(nil), AnyOf(Cat{}, Dog{}))
+	doc.
+		Paths(
+			NewPath("/pets").Post().
+				JSONBody(OneOf(Cat{}, Dog{}).Discriminator("kind", map[string]any{"cat": Cat{}, "dog": Dog{}})).
+				Responses(NewResponse(200).JSON(Zoo{}).Description("ok")),
+		)
+
+	buffer := bytes.NewBuffer(nil)
+	err := doc.Write(buffer, 2)
+	fmt.Println(buffer.String())
+	require.NoError(t, err)
+	assert.Equal(t, testBuilderPolymorphicExpectedSpecs, strings.ReplaceAll(buffer.String(), "\n", ""))
+
+	doc = &Document{}
+	doc.RegisterInterface((*Animal)(nil), OneOf(Cat{}, Error{}))
+	doc.Path(NewPath("/pets").Post().JSONBody(OneOf(Cat{}, Dog{}).Discriminator("type", map[string]any{"cat": Cat{}, "kid": Kid{}})))
+	err = doc.Write(bytes.NewBuffer(nil), 2)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "does not implement openapigen.Animal")
+	assert.Contains(t, err.Error(), `discriminator value "kid"`)
+	assert.Contains(t, err.Error(), `discriminator property "type" not found`)
+}
+
+type Event struct {
+	Payload  any            `json:"payload"`
+	Metadata map[string]any `json:"metadata"`
+}
+
+func TestBuilderInterfaces(t *testing.T) {
+
+	properties, _, err := Properties(Event{})
+	require.NoError(t, err)
+	require.Len(t, properties, 2)
+	assert.Equal(t, &openapi3.Schema{}, oapiSchemaFromProperty(&properties[0]).Value)
+	assert.Equal(t, &openapi3.Schema{}, oapiSchemaFromProperty(&properties[1]).Value.AdditionalProperties.Schema.Value)
+
+	_, _, err = Properties(Zoo{})
+	require.ErrorIs(t, err, ErrUnsupportedType)
+	assert.Contains(t, err.Error(), "interface openapigen.Animal has no registered implementations")
+}
+
+func TestBuilderDeterministic(t *testing.T) {
+
+	newDoc := func() *Document {
+		doc := &Document{Title: "zoo", Version: "1.0"}
+		doc.Tags(Tag{Name: "animals"}).
+			SecurityScheme("apiKey", NewAPIKeyScheme(HEADER, "X-API-Key")).
+			SecurityScheme("basic", NewBasicAuthScheme()).
+			Security("apiKey").
+			RegisterInterface((*Animal)(nil), OneOf(Cat{}, Dog{})).
+			Paths(
+				NewPath("/zoo").Delete().Responses(NewResponse(204).Description("deleted")),
+				NewPath("/zoo").Post().JSONBody(Zoo{}).Responses(NewResponse(201).JSON(Zoo{}).Description("created")),
+				NewPath("/zoo").Get().Parameter(OrderByQueryParam).Responses(NewResponse(200).JSON(Zoo{}).Description("ok")),
+				NewPath("/accounts").Get().Security("basic").Responses(NewResponse(200).JSON(Account{}).Description("ok")),
+				NewPath("/people").Put().JSONBody(Person{}).Responses(NewResponse(200).JSON(Persons{}).Description("ok")),
+			)
+		return doc
+	}
+
+	expected := bytes.NewBuffer(nil)
+	require.NoError(t, newDoc().Write(expected, 2))
+
+	doc := newDoc()
+	for range 20 {
+		buffer := bytes.NewBuffer(nil)
+		require.NoError(t, doc.Write(buffer, 2))
+		require.Equal(t, expected.String(), buffer.String())
+
+		buffer.Reset()
+		require.NoError(t, newDoc().Write(buffer, 2))
+		require.Equal(t, expected.String(), buffer.String())
+	}
+
+	output := expected.String()
+	assert.Equal(t, 1, strings.Count(output, "- name: animals"))
+	zoo := output[strings.Index(output, "  /zoo:"):]
+	get, post, del := strings.Index(zoo, "    get:"), strings.Index(zoo, "    post:"), strings.Index(zoo, "    delete:")
+	assert.True(t, get < post && post < del, "operations are not in http method order")
+}
+
+type Cookie struct {
+	Value string `json:"value"`
+}
+
+type Session struct {
+	Local  Cookie      `json:"local"`
+	Remote http.Cookie `json:"remote"`
+}
+
+type Version struct {
+	Major int `json:"major"`
+}
+
+func (Version) SchemaName() string { return "ApiVersion" }
+
+func TestBuilderNameCollisions(t *testing.T) {
+
+	newDoc := func() *Document {
+		return (&Document{}).Paths(
+			NewPath("/sessions").Get().Responses(NewResponse(200).JSON(Session{}).Description("ok")),
+			NewPath("/version").Get().Responses(NewResponse(200).JSON(Version{}).Description("ok")),
+		)
+	}
+
+	err := newDoc().Build()
+	require.ErrorIs(t, err, ErrNameCollision)
+	require.Len(t, splitErrors(err), 1)
+	assert.Contains(t, err.Error(), "Cookie is used by both openapigen.Cookie and http.Cookie")
+
+	doc := newDoc().NameCollisions(PackagePrefix)
+	require.NoError(t, doc.Build())
+	schemas := doc.t.Components.Schemas
+	assert.Contains(t, schemas, "Cookie")
+	assert.Contains(t, schemas, "HttpCookie")
+	assert.Contains(t, schemas, "ApiVersion")
+	assert.NotContains(t, schemas, "Version")
+	assert.Equal(t, "#/components/schemas/HttpCookie", schemas["Session"].Value.Properties["remote"].Ref)
+
+	// a strategy giving an already used name
+	err = newDoc().NameCollisions(func(reflect.Type, string) string { return "Session" }).Build()
+	require.ErrorIs(t, err, ErrNameCollision)
+}
+
+func TestBuilderEquivalentSlices(t *testing.T) {
+
+	doc := (&Document{}).Paths(
+		NewPath("/persons").Get().Responses(NewResponse(200).JSON([]Person{}).Description("ok")),
+		NewPath("/people").Get().Responses(NewResponse(200).JSON(Persons{}).Description("ok")),
+	)
+	require.NoError(t, doc.Build())
+
+	schemas := doc.t.Components.Schemas
+	require.Contains(t, schemas, "Persons")
+	assert.Equal(t, "#/components/schemas/Person", schemas["Persons"].Value.Items.Ref)
+	for _, path := range []string{"/persons", "/people"} {
+		response := doc.t.Paths.Value(path).Get.Responses.Value("200").Value
+		assert.Equal(t, "#/components/schemas/Persons", response.Content.Get("application/json").Schema.Ref)
+	}
+}
+
+type Node struct {
+	Name     string `json:"name"`
+	Parent   *Node  `json:"parent,omitempty"`
+	Children []Node `json:"children"`
+}
+
+type Comment struct {
+	Text    string  `json:"text"`
+	Replies []Reply `json:"replies"`
+}
+
+type Reply struct {
+	Text     string   `json:"text"`
+	Comments Comments `json:"comments"`
+}
+
+type Comments []Comment
+
+type Chain struct {
+	*Chain
+	Value int `json:"value"`
+}
+
+func TestBuilderRecursive(t *testing.T) {
+
+	doc := &Document{}
+	doc.
+		Paths(
+			NewPath("/tree").Get().
+				Responses(NewResponse(200).JSON(Node{}).Description("ok")),
+			NewPath("/comments").Get().
+				Responses(NewResponse(200).JSON(Comments{}).Description("ok")),
+			NewPath("/comments").Post().
+				JSONBody(Comment{}).
+				Responses(NewResponse(201).JSON(Reply{}).Description("created")),
+		)
+
+	buffer := bytes.NewBuffer(nil)
+	err := doc.Write(buffer, 2)
+	fmt.Println(buffer.String())
+	require.NoError(t, err)
+	assert.Equal(t, testBuilderRecursiveExpectedSpecs, strings.ReplaceAll(buffer.String(), "\n", ""))
+
+	// a struct embedding itself is not flattened again
+	properties, _, err := Properties(Chain{})
+	require.NoError(t, err)
+	require.Len(t, properties, 1)
+	assert.Equal(t, "value", properties[0].name)
+}
+
+type Stats struct {
+	Count int `json:"count"`
+}
+
+type Report struct {
+	Name    string `json:"name"`
+	Stats   Stats  `json:"stats" oapi:"inline"`
+	History []struct {
+		Day   time.Time `json:"day"`
+		Stats Stats     `json:"stats"`
+	} `json:"history"`
+}
+
+func TestBuilderInline(t *testing.T) {
+
+	doc := &Document{}
+	doc.
+		Paths(
+			NewPath("/reports").Post().
+				JSONBodyInline(Report{}, true).
+				Responses(
+					NewResponse(200).JSON(struct {
+						Total int `json:"total"`
+					}{}).Description("ok"),
+					NewResponse(201).JSONInline(Stats{}).Description("created"),
+				),
+			NewPath("/reports").Get().
+				Responses(
+					NewResponse(200).JSON([]string{}).Description("names"),
+					NewResponse(206).JSON([][]Movie{}).Description("pages"),
+					NewResponse(300).JSON([3]Movie{}).Description("podium"),
+				),
+		)
+
+	buffer := bytes.NewBuffer(nil)
+	err := doc.Write(buffer, 2)
+	fmt.Println(buffer.String())
+	require.NoError(t, err)
+	assert.Equal(t, testBuilderInlineExpectedSpecs, strings.ReplaceAll(buffer.String(), "\n", ""))
+	assert.NotContains(t, doc.t.Components.Schemas, "strings")
+	assert.NotContains(t, doc.t.Components.Schemas, "s")
+
+	_, err = NewSchema([3]Movie{}).RefPath()
+	require.ErrorIs(t, err, ErrUnsupportedType)
+	assert.EqualError(t, err, "type [3]openapigen.Movie: type not supported: array has no component name")
+}
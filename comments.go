@@ -0,0 +1,210 @@
+package openapigen
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// docComments are the doc comments of the types of some packages, keyed by
+// the import path and the name of the types.
+type docComments struct {
+	types  map[string]string
+	fields map[string]map[string]string
+}
+
+// DocComments reads the doc comments of the types declared in the given
+// package directories, they describe the schemas and the properties which
+// have no description tag. The import path of a directory is found from the
+// go.mod of its module, the types of a main package are the ones of the
+// program building the document.
+func (d *Document) DocComments(dirs ...string) *Document {
+	if d.comments == nil {
+		d.comments = &docComments{types: make(map[string]string), fields: make(map[string]map[string]string)}
+	}
+	for _, dir := range dirs {
+		if err := d.comments.load(dir); err != nil {
+			d.errs = append(d.errs, fmt.Errorf("doc comments of %s: %w", dir, err))
+		}
+	}
+	return d
+}
+
+func (c *docComments) load(dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return errors.New("no go files")
+	}
+	var pkgPath string
+	fset := token.NewFileSet()
+	for _, filename := range files {
+		file, err := parser.ParseFile(fset, filename, nil, parser.ParseComments)
+		if err != nil {
+			return err
+		}
+		// external test packages are other packages
+		if strings.HasSuffix(file.Name.Name, "_test") {
+			continue
+		}
+		if pkgPath == "" {
+			if pkgPath, err = packagePath(dir, file.Name.Name); err != nil {
+				return err
+			}
+		}
+		for _, decl := range file.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				doc := typeSpec.Doc
+				if doc == nil && len(genDecl.Specs) == 1 {
+					doc = genDecl.Doc
+				}
+				key := pkgPath + "." + typeSpec.Name.Name
+				if text := commentText(doc); text != "" {
+					c.types[key] = text
+				}
+				if structType, ok := typeSpec.Type.(*ast.StructType); ok {
+					c.loadFields(key, structType)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (c *docComments) loadFields(key string, structType *ast.StructType) {
+	for _, field := range structType.Fields.List {
+		text := commentText(field.Doc)
+		if text == "" {
+			text = commentText(field.Comment)
+		}
+		if text == "" {
+			continue
+		}
+		if c.fields[key] == nil {
+			c.fields[key] = make(map[string]string)
+		}
+		for _, name := range field.Names {
+			c.fields[key][name.Name] = text
+		}
+		if len(field.Names) == 0 { // embedded field
+			c.fields[key][embeddedName(field.Type)] = text
+		}
+	}
+}
+
+func embeddedName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return embeddedName(e.X)
+	case *ast.SelectorExpr:
+		return e.Sel.Name
+	case *ast.IndexExpr:
+		return embeddedName(e.X)
+	case *ast.IndexListExpr:
+		return embeddedName(e.X)
+	case *ast.Ident:
+		return e.Name
+	}
+	return ""
+}
+
+func commentText(group *ast.CommentGroup) string {
+	if group == nil {
+		return ""
+	}
+	return strings.TrimSpace(group.Text())
+}
+
+// packagePath returns the package path reflect reports for the types of a
+// package directory: main for a command, its import path otherwise.
+func packagePath(dir, name string) (string, error) {
+	if name == "main" {
+		return "main", nil
+	}
+	return importPath(dir)
+}
+
+// importPath returns the import path of a package directory, from the module
+// path declared in the go.mod of its module.
+func importPath(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for root := dir; ; root = filepath.Dir(root) {
+		modulePath, err := modulePath(filepath.Join(root, "go.mod"))
+		if err == nil {
+			rel, err := filepath.Rel(root, dir)
+			if err != nil {
+				return "", err
+			}
+			if rel == "." {
+				return modulePath, nil
+			}
+			return modulePath + "/" + filepath.ToSlash(rel), nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+		if filepath.Dir(root) == root {
+			return "", errors.New("go.mod not found")
+		}
+	}
+}
+
+func modulePath(gomod string) (string, error) {
+	file, err := os.Open(gomod)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if fields := strings.Fields(scanner.Text()); len(fields) == 2 && fields[0] == "module" {
+			return strings.Trim(fields[1], `"`), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("%s: module path not found", gomod)
+}
+
+// typeKey returns the key of a named type in the doc comments.
+func typeKey(_type reflect.Type) string {
+	name, _, _ := strings.Cut(_type.Name(), "[")
+	return _type.PkgPath() + "." + name
+}
+
+// typeDoc returns the doc comment of a type, empty if there is none.
+func (c *docComments) typeDoc(_type reflect.Type) string {
+	for _type.Kind() == reflect.Pointer {
+		_type = _type.Elem()
+	}
+	if c == nil || _type.Name() == "" {
+		return ""
+	}
+	return c.types[typeKey(_type)]
+}
+
+// fieldDoc returns the doc comment of a struct field, empty if there is none.
+func (c *docComments) fieldDoc(_type reflect.Type, field string) string {
+	if c == nil || _type.Name() == "" {
+		return ""
+	}
+	return c.fields[typeKey(_type)][field]
+}
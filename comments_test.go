@@ -0,0 +1,72 @@
+package openapigen
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Book is a book of the library, identified by its ISBN.
+type Book struct {
+	// ISBN is the international standard book number, with or without dashes.
+	ISBN   string `json:"isbn"`
+	Title  string `json:"title"` // title in the original language
+	Author string `json:"author" oapi:"description:full name"`
+	Pages  int    `json:"pages"`
+	// Genre, like fiction or poetry.
+	Genre Genre `json:"genre"`
+}
+
+// Books are the books of a shelf.
+type Books []Book
+
+type (
+	// Genre of a book.
+	Genre string
+)
+
+func (Genre) Values() []any { return []any{"fiction", "poetry"} }
+
+func TestDocComments(t *testing.T) {
+
+	doc := &Document{}
+	doc.
+		DocComments(".").
+		Paths(
+			NewPath("/books").Get().
+				Responses(NewResponse(200).JSON(Books{}).Description("ok")),
+		)
+
+	buffer := bytes.NewBuffer(nil)
+	err := doc.Write(buffer, 2)
+	fmt.Println(buffer.String())
+	require.NoError(t, err)
+	assert.Equal(t, testDocCommentsExpectedSpecs, strings.ReplaceAll(buffer.String(), "\n", ""))
+
+	err = (&Document{}).DocComments("./missing").Build()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "doc comments of ./missing")
+}
+
+func TestImportPath(t *testing.T) {
+	path, err := importPath("utils")
+	require.NoError(t, err)
+	assert.Equal(t, "github.com/fmarmol/openapigen/utils", path)
+}
+
+func TestDocCommentsMain(t *testing.T) {
+	dir := t.TempDir()
+	source := "package main\n\n// Widget is built by the command.\ntype Widget struct {\n\tName string // name of the widget\n}\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte(source), 0o600))
+
+	comments := &docComments{types: make(map[string]string), fields: make(map[string]map[string]string)}
+	require.NoError(t, comments.load(dir))
+	assert.Equal(t, "Widget is built by the command.", comments.types["main.Widget"])
+	assert.Equal(t, "name of the widget", comments.fields["main.Widget"]["Name"])
+}
@@ -0,0 +1,176 @@
+package openapigen
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+
+	"github.com/fmarmol/kin-openapi/openapi3"
+)
+
+// RequestBody describes the body of a request, it is set on a path with
+// Path.RequestBody and can be shared by several operations as a component.
+type RequestBody struct {
+	description   string
+	contents      contents
+	required      bool
+	componentName string
+}
+
+func NewRequestBody() *RequestBody {
+	return new(RequestBody)
+}
+
+// Content adds a content type to the body, described by the type of obj. A
+// body can have several content types.
+func (b *RequestBody) Content(content string, obj any) *RequestBody {
+	return b.MediaType(content, NewMediaType(obj))
+}
+
+// MediaType adds a content type to the body, with its examples and its
+// encoding.
+func (b *RequestBody) MediaType(content string, m *MediaType) *RequestBody {
+	b.contents.set(content, m)
+	return b
+}
+
+func (b *RequestBody) JSON(obj any) *RequestBody {
+	return b.Content("application/json", obj)
+}
+
+func (b *RequestBody) Description(s string) *RequestBody {
+	b.description = s
+	return b
+}
+
+func (b *RequestBody) Required() *RequestBody {
+	b.required = true
+	return b
+}
+
+// Example adds a named example to the content of the body declared last.
+func (b *RequestBody) Example(name string, e *Example) *RequestBody {
+	b.contents.example(name, e)
+	return b
+}
+
+// AsComponent declares the body in the components of the document, the
+// operations reference it instead of repeating it.
+func (b *RequestBody) AsComponent(name string) *RequestBody {
+	b.componentName = name
+	return b
+}
+
+// ResponseHeader describes a header of a response with the type of a go value,
+// it can be shared by several responses as a component.
+type ResponseHeader struct {
+	_type         reflect.Type
+	description   string
+	componentName string
+}
+
+func NewResponseHeader(obj any) *ResponseHeader {
+	return &ResponseHeader{_type: reflect.TypeOf(obj)}
+}
+
+func (h *ResponseHeader) Description(s string) *ResponseHeader {
+	h.description = s
+	return h
+}
+
+// AsComponent declares the header in the components of the document, the
+// responses reference it instead of repeating it.
+func (h *ResponseHeader) AsComponent(name string) *ResponseHeader {
+	h.componentName = name
+	return h
+}
+
+// Example is a sample value of a request or response content, it can be
+// shared by several operations as a component.
+type Example struct {
+	value         openapi3.Example
+	componentName string
+}
+
+func NewExample(value any) *Example {
+	return &Example{value: openapi3.Example{Value: value}}
+}
+
+func (e *Example) Summary(s string) *Example {
+	e.value.Summary = s
+	return e
+}
+
+func (e *Example) Description(s string) *Example {
+	e.value.Description = s
+	return e
+}
+
+// AsComponent declares the example in the components of the document, the
+// operations reference it instead of repeating it.
+func (e *Example) AsComponent(name string) *Example {
+	e.componentName = name
+	return e
+}
+
+// components holds the components declared by the operations of a path, they
+// are added to the document once the path is built.
+type components struct {
+	parameters    map[string]*openapi3.ParameterRef
+	responses     map[string]*openapi3.ResponseRef
+	requestBodies map[string]*openapi3.RequestBodyRef
+	headers       map[string]*openapi3.HeaderRef
+	examples      map[string]*openapi3.ExampleRef
+}
+
+func newComponents() components {
+	return components{
+		parameters:    make(map[string]*openapi3.ParameterRef),
+		responses:     make(map[string]*openapi3.ResponseRef),
+		requestBodies: make(map[string]*openapi3.RequestBodyRef),
+		headers:       make(map[string]*openapi3.HeaderRef),
+		examples:      make(map[string]*openapi3.ExampleRef),
+	}
+}
+
+// registerComponents adds the components of a path to the document, a name
+// can only be used by identical components.
+func (d *Document) registerComponents(c components) []error {
+	t := d.t.Components
+	if t.Parameters == nil {
+		t.Parameters = make(openapi3.ParametersMap)
+	}
+	var errs []error
+	errs = append(errs, registerComponents("parameter", t.Parameters, c.parameters)...)
+	if len(c.responses) > 0 && t.Responses == nil {
+		t.Responses = make(openapi3.ResponseBodies)
+	}
+	errs = append(errs, registerComponents("response", t.Responses, c.responses)...)
+	if len(c.requestBodies) > 0 && t.RequestBodies == nil {
+		t.RequestBodies = make(openapi3.RequestBodies)
+	}
+	errs = append(errs, registerComponents("request body", t.RequestBodies, c.requestBodies)...)
+	if len(c.headers) > 0 && t.Headers == nil {
+		t.Headers = make(openapi3.Headers)
+	}
+	errs = append(errs, registerComponents("header", t.Headers, c.headers)...)
+	if len(c.examples) > 0 && t.Examples == nil {
+		t.Examples = make(openapi3.Examples)
+	}
+	errs = append(errs, registerComponents("example", t.Examples, c.examples)...)
+	return errs
+}
+
+func registerComponents[T any](kind string, dst, src map[string]T) []error {
+	var errs []error
+	for _, name := range slices.Sorted(maps.Keys(src)) {
+		value := src[name]
+		if existing, ok := dst[name]; ok && !reflect.DeepEqual(existing, value) {
+			errs = append(errs, fmt.Errorf("%s component %s is declared with different values", kind, name))
+			continue
+		}
+		dst[name] = value
+	}
+	return errs
+}
@@ -0,0 +1,131 @@
+package openapigen
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type ProblemDetails struct {
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+}
+
+var (
+	RateLimitRemaining = NewResponseHeader(0).Description("requests left in the window").AsComponent("RateLimitRemaining")
+	NotFoundResponse   = NewResponse(404).Description("not found").JSON(ProblemDetails{}).AsComponent("NotFound")
+	MovieExample       = NewExample(map[string]any{"title": "Alien", "year": 1979}).Summary("a classic").AsComponent("Alien")
+	MovieBody          = NewRequestBody().JSON(Movie{}).Description("movie to save").Required().Example("alien", MovieExample).AsComponent("MovieBody")
+)
+
+func TestComponents(t *testing.T) {
+
+	doc := &Document{}
+	doc.
+		Paths(
+			NewPath("/movies").Post().
+				RequestBody(MovieBody).
+				Responses(
+					NewResponse(201).Description("created").JSON(Movie{}).
+						AddHeader("X-RateLimit-Remaining", RateLimitRemaining).
+						Example("alien", MovieExample),
+				),
+			NewPath("/movies/{id}").Put().
+				RequestBody(MovieBody).
+				Responses(
+					NewResponse(200).Description("updated").JSON(Movie{}).
+						AddHeader("X-RateLimit-Remaining", RateLimitRemaining).
+						Example("inline", NewExample(map[string]any{"title": "Heat"})),
+					NotFoundResponse,
+				),
+			NewPath("/movies/{id}").Delete().
+				Responses(NewResponse(204).Description("deleted"), NotFoundResponse),
+		)
+
+	buffer := bytes.NewBuffer(nil)
+	err := doc.Write(buffer, 2)
+	fmt.Println(buffer.String())
+	require.NoError(t, err)
+	assert.Equal(t, testComponentsExpectedSpecs, strings.ReplaceAll(buffer.String(), "\n", ""))
+}
+
+func TestComponentsErrors(t *testing.T) {
+
+	doc := &Document{}
+	doc.
+		Paths(
+			NewPath("/movies").Get().
+				Responses(NewResponse(404).Description("not found").AsComponent("NotFound")),
+			NewPath("/studios").Get().
+				Responses(
+					NewResponse(404).Description("no studio").AsComponent("NotFound"),
+					NewResponse(200).Description("ok").Example("empty", NewExample([]any{})),
+				),
+		)
+
+	err := doc.Build()
+	require.Error(t, err)
+
+	errs := splitErrors(err)
+	require.Len(t, errs, 2)
+	assert.EqualError(t, errs[0], "GET /studios: response 200: example empty requires a content")
+	assert.EqualError(t, errs[1], "GET /studios: response component NotFound is declared with different values")
+}
+
+func TestComponentsRequestBodyCopy(t *testing.T) {
+
+	doc := &Document{}
+	doc.
+		Paths(
+			NewPath("/movies/import").Post().
+				RequestBody(MovieBody).
+				FormData(Upload{}).
+				Responses(NewResponse(204).Description("imported")),
+			NewPath("/movies").Post().
+				RequestBody(MovieBody).
+				Responses(NewResponse(204).Description("created")),
+		)
+	require.NoError(t, doc.Build())
+	assert.Len(t, MovieBody.contents.mediaTypes, 1)
+
+	component := doc.t.Components.RequestBodies["MovieBody"]
+	require.NotNil(t, component)
+	assert.Len(t, component.Value.Content, 1)
+	assert.Equal(t, "#/components/requestBodies/MovieBody", doc.t.Paths.Value("/movies").Post.RequestBody.Ref)
+
+	body := doc.t.Paths.Value("/movies/import").Post.RequestBody
+	assert.Equal(t, "", body.Ref)
+	assert.Len(t, body.Value.Content, 2)
+	assert.True(t, body.Value.Required)
+}
+
+func TestComponentsHeaderRef(t *testing.T) {
+
+	doc := &Document{}
+	doc.
+		Paths(
+			NewPath("/tasks").Post().
+				RequestBody(nil).
+				Responses(
+					NewResponse(201).Description("created").
+						AddHeader("X-Priority", NewResponseHeader(Priority(0)).Description("priority of the task")),
+				),
+		)
+
+	err := doc.Build()
+	require.EqualError(t, err, "POST /tasks: request body is nil")
+
+	doc = &Document{}
+	doc.Path(NewPath("/tasks").Post().Responses(
+		NewResponse(201).Description("created").
+			AddHeader("X-Priority", NewResponseHeader(Priority(0)).Description("priority of the task")),
+	))
+	require.NoError(t, doc.Build())
+	header := doc.t.Paths.Value("/tasks").Post.Responses.Value("201").Value.Headers["X-Priority"].Value
+	assert.Equal(t, "priority of the task", header.Description)
+	assert.Equal(t, "#/components/schemas/Priority", header.Schema.Ref)
+}
@@ -0,0 +1,144 @@
+package openapigen
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/fmarmol/kin-openapi/openapi3"
+)
+
+// MediaType describes one content type of a request body or a response: the
+// schema of a go value, its examples and, for multipart and form contents, the
+// encoding of its properties.
+type MediaType struct {
+	ref      *Schema
+	examples map[string]*Example
+	encoding map[string]*Encoding
+}
+
+func NewMediaType(obj any) *MediaType {
+	return &MediaType{ref: NewSchema(obj)}
+}
+
+// Inline describes the object in place instead of referencing the component
+// of its type.
+func (m *MediaType) Inline() *MediaType {
+	m.ref.inline = true
+	return m
+}
+
+func (m *MediaType) Example(name string, e *Example) *MediaType {
+	if m.examples == nil {
+		m.examples = make(map[string]*Example)
+	}
+	m.examples[name] = e
+	return m
+}
+
+// Encoding describes how a property of a multipart or form content is
+// serialized.
+func (m *MediaType) Encoding(property string, e *Encoding) *MediaType {
+	if m.encoding == nil {
+		m.encoding = make(map[string]*Encoding)
+	}
+	m.encoding[property] = e
+	return m
+}
+
+// Encoding describes how a property of a multipart or form content is
+// serialized, like the content type of an uploaded file.
+type Encoding struct {
+	value openapi3.Encoding
+}
+
+func NewEncoding(contentType string) *Encoding {
+	return &Encoding{value: openapi3.Encoding{ContentType: contentType}}
+}
+
+// Style sets how a property of a form content is serialized, like the style
+// of a query parameter.
+func (e *Encoding) Style(s string) *Encoding {
+	e.value.Style = s
+	return e
+}
+
+func (e *Encoding) Explode(v bool) *Encoding {
+	e.value.Explode = &v
+	return e
+}
+
+func (e *Encoding) AllowReserved() *Encoding {
+	e.value.AllowReserved = true
+	return e
+}
+
+// contents holds the media types of a request body or a response by content
+// type. Examples are added to the content declared last, examples declared
+// before any content are reported when the path is built.
+type contents struct {
+	mediaTypes map[string]*MediaType
+	last       string
+	orphans    []string // names of the examples declared without content
+}
+
+func (c *contents) set(content string, m *MediaType) {
+	if c.mediaTypes == nil {
+		c.mediaTypes = make(map[string]*MediaType)
+	}
+	c.mediaTypes[content] = m
+	c.last = content
+}
+
+// clone returns a copy of the contents, the media types are shared.
+func (c contents) clone() contents {
+	c.mediaTypes = maps.Clone(c.mediaTypes)
+	c.orphans = slices.Clone(c.orphans)
+	return c
+}
+
+func (c *contents) example(name string, e *Example) {
+	if m, ok := c.mediaTypes[c.last]; ok {
+		m.Example(name, e)
+		return
+	}
+	c.orphans = append(c.orphans, name)
+}
+
+// content builds the media types of a request body or a response.
+func (p *Path) content(refl *reflector, c contents) (openapi3.Content, error) {
+	var errs []error
+	for _, name := range c.orphans {
+		errs = append(errs, fmt.Errorf("example %s requires a content", name))
+	}
+	if len(c.mediaTypes) == 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	ret := make(openapi3.Content, len(c.mediaTypes))
+	for _, content := range slices.Sorted(maps.Keys(c.mediaTypes)) {
+		m := c.mediaTypes[content]
+		mediaType := &openapi3.MediaType{
+			Schema:   p.schemaRef(refl, m.ref),
+			Examples: p.examples(m.examples),
+		}
+		if len(m.encoding) > 0 {
+			if !isFormContent(content) {
+				errs = append(errs, fmt.Errorf("content %s: encoding only applies to multipart and form contents", content))
+			}
+			mediaType.Encoding = make(map[string]*openapi3.Encoding, len(m.encoding))
+			for property, e := range m.encoding {
+				value := e.value
+				mediaType.Encoding[property] = &value
+			}
+		}
+		ret[content] = mediaType
+	}
+	return ret, errors.Join(errs...)
+}
+
+func isFormContent(content string) bool {
+	return strings.HasPrefix(content, "multipart/") || content == "application/x-www-form-urlencoded"
+}
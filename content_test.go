@@ -0,0 +1,65 @@
+package openapigen
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type Upload struct {
+	Name   string   `json:"name"`
+	File   []byte   `json:"file" oapi:"format:binary"`
+	Labels []string `json:"labels,omitempty"`
+}
+
+type CSVString string
+
+func TestContents(t *testing.T) {
+
+	doc := &Document{}
+	doc.
+		Paths(
+			NewPath("/kid").Get().
+				Responses(
+					NewResponse(200).Description("kid").
+						JSON(Kid{}).
+						Example("ada", NewExample(map[string]any{"my_name": "Ada"})).
+						Content("text/csv", CSVString("")).
+						Example("csv", NewExample("my_name\nAda")),
+				),
+			NewPath("/uploads").Post().
+				JSONBody(Upload{}, true).
+				MediaType("multipart/form-data", NewMediaType(Upload{}).
+					Encoding("file", NewEncoding("image/png, image/jpeg")).
+					Encoding("labels", NewEncoding("text/plain").Style("form").Explode(true))).
+				Responses(NewResponse(204).Description("ok")),
+		)
+
+	buffer := bytes.NewBuffer(nil)
+	err := doc.Write(buffer, 2)
+	fmt.Println(buffer.String())
+	require.NoError(t, err)
+	assert.Equal(t, testContentsExpectedSpecs, strings.ReplaceAll(buffer.String(), "\n", ""))
+}
+
+func TestContentsErrors(t *testing.T) {
+
+	doc := &Document{}
+	doc.
+		Paths(
+			NewPath("/uploads").Post().
+				MediaType("application/json", NewMediaType(Upload{}).Encoding("file", NewEncoding("image/png"))).
+				Responses(NewResponse(204).Description("ok")),
+		)
+
+	err := doc.Build()
+	require.Error(t, err)
+
+	errs := splitErrors(err)
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], "POST /uploads: request body: content application/json: encoding only applies to multipart and form contents")
+}
@@ -0,0 +1,184 @@
+package openapigen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Format string
+
+const (
+	YAML Format = "yaml"
+	JSON Format = "json"
+)
+
+const defaultIndent = 2
+
+// Encode builds the document and writes it in the given format.
+func (d *Document) Encode(w io.Writer, format Format) error {
+	switch format {
+	case YAML:
+		return d.Write(w, defaultIndent)
+	case JSON:
+		return d.WriteJSON(w, defaultIndent)
+	default:
+		return fmt.Errorf("format %s not supported", format)
+	}
+}
+
+// WriteJSON builds the document and writes it in JSON, keys are in the same
+// order as in the YAML output.
+func (d *Document) WriteJSON(w io.Writer, indent int) error {
+	raw, err := d.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	buffer := bytes.NewBuffer(nil)
+	if err := json.Indent(buffer, raw, "", strings.Repeat(" ", indent)); err != nil {
+		return err
+	}
+	buffer.WriteByte('\n')
+	_, err = buffer.WriteTo(w)
+	return err
+}
+
+func (d *Document) MarshalYAML() (any, error) {
+	return d.node()
+}
+
+func (d *Document) MarshalJSON() ([]byte, error) {
+	node, err := d.node()
+	if err != nil {
+		return nil, err
+	}
+	buffer := bytes.NewBuffer(nil)
+	if err := writeJSONNode(buffer, node); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// node builds the document and returns it as an ordered yaml tree, from which
+// both the YAML and the JSON outputs are written.
+func (d *Document) node() (*yaml.Node, error) {
+	err := d.Build()
+	if err != nil {
+		return nil, err
+	}
+	finalDoc, err := NewYamlDocument(d)
+	if err != nil {
+		return nil, err
+	}
+	node := new(yaml.Node)
+	if err := node.Encode(finalDoc); err != nil {
+		return nil, err
+	}
+	for _, key := range []string{"paths", "webhooks"} {
+		if pathItems := mappingValue(node, key); pathItems != nil {
+			for i := 1; i < len(pathItems.Content); i += 2 {
+				sortOperations(pathItems.Content[i])
+			}
+		}
+	}
+	return node, nil
+}
+
+// methodsOrder is the order of the operations in a path item, the other fields
+// of the path item come first.
+var methodsOrder = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace", "connect"}
+
+func sortOperations(pathItem *yaml.Node) {
+	type pair struct{ key, value *yaml.Node }
+	pairs := make([]pair, 0, len(pathItem.Content)/2)
+	for i := 0; i+1 < len(pathItem.Content); i += 2 {
+		pairs = append(pairs, pair{pathItem.Content[i], pathItem.Content[i+1]})
+	}
+	slices.SortStableFunc(pairs, func(a, b pair) int {
+		return slices.Index(methodsOrder, a.key.Value) - slices.Index(methodsOrder, b.key.Value)
+	})
+	pathItem.Content = pathItem.Content[:0]
+	for _, p := range pairs {
+		pathItem.Content = append(pathItem.Content, p.key, p.value)
+	}
+}
+
+// mappingValue returns the value of a key of a yaml mapping.
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func writeJSONNode(buffer *bytes.Buffer, node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			if err := writeJSONNode(buffer, child); err != nil {
+				return err
+			}
+		}
+	case yaml.MappingNode:
+		buffer.WriteByte('{')
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if i > 0 {
+				buffer.WriteByte(',')
+			}
+			key, err := json.Marshal(node.Content[i].Value)
+			if err != nil {
+				return err
+			}
+			buffer.Write(key)
+			buffer.WriteByte(':')
+			if err := writeJSONNode(buffer, node.Content[i+1]); err != nil {
+				return err
+			}
+		}
+		buffer.WriteByte('}')
+	case yaml.SequenceNode:
+		buffer.WriteByte('[')
+		for i, child := range node.Content {
+			if i > 0 {
+				buffer.WriteByte(',')
+			}
+			if err := writeJSONNode(buffer, child); err != nil {
+				return err
+			}
+		}
+		buffer.WriteByte(']')
+	case yaml.AliasNode:
+		return writeJSONNode(buffer, node.Alias)
+	case yaml.ScalarNode:
+		switch node.ShortTag() {
+		case "!!null":
+			buffer.WriteString("null")
+		case "!!bool", "!!int":
+			buffer.WriteString(node.Value)
+		case "!!float":
+			var value float64
+			if err := node.Decode(&value); err != nil {
+				return err
+			}
+			raw, err := json.Marshal(value)
+			if err != nil {
+				return err
+			}
+			buffer.Write(raw)
+		default:
+			raw, err := json.Marshal(node.Value)
+			if err != nil {
+				return err
+			}
+			buffer.Write(raw)
+		}
+	}
+	return nil
+}
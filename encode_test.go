@@ -0,0 +1,54 @@
+package openapigen
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestEncodeJSON(t *testing.T) {
+
+	newDoc := func() *Document {
+		doc := &Document{Title: "movies", Version: "1.0"}
+		return doc.Server("/api").Paths(
+			NewPath("/movies").Get().
+				Parameter(NewParameter("year").InQuery().Type("integer").Min(1900)).
+				Responses(NewResponse(200).JSON([]Movie{}).Description("ok")),
+		)
+	}
+
+	buffer := bytes.NewBuffer(nil)
+	require.NoError(t, newDoc().WriteJSON(buffer, 2))
+	assert.Equal(t, testEncodeJSONExpectedSpecs, buffer.String())
+
+	raw, err := json.Marshal(newDoc())
+	require.NoError(t, err)
+	assert.JSONEq(t, testEncodeJSONExpectedSpecs, string(raw))
+
+	// same content and same key ordering in both formats
+	yamlBuffer := bytes.NewBuffer(nil)
+	require.NoError(t, newDoc().Encode(yamlBuffer, YAML))
+	var fromYAML, fromJSON yaml.Node
+	require.NoError(t, yaml.Unmarshal(yamlBuffer.Bytes(), &fromYAML))
+	require.NoError(t, yaml.Unmarshal(buffer.Bytes(), &fromJSON))
+	assert.Equal(t, keys(&fromYAML), keys(&fromJSON))
+
+	require.EqualError(t, newDoc().Encode(buffer, "xml"), "format xml not supported")
+}
+
+// keys lists the keys of a yaml tree in document order.
+func keys(node *yaml.Node) []string {
+	var ret []string
+	for i, child := range node.Content {
+		if node.Kind == yaml.MappingNode && i%2 == 0 {
+			ret = append(ret, child.Value)
+			continue
+		}
+		ret = append(ret, keys(child)...)
+	}
+	return ret
+}
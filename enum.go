@@ -1,5 +1,13 @@
 package openapigen
 
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/fmarmol/kin-openapi/openapi3"
+)
+
 type Enum interface {
 	Values() []any
 }
@@ -13,3 +21,112 @@ func (e enums) Values() []any {
 func Enums(val ...any) Enum {
 	return enums{val}
 }
+
+// EnumNamer is implemented by enums naming their values, in the order of
+// Values. The names are emitted as x-enum-varnames.
+type EnumNamer interface {
+	EnumNames() []string
+}
+
+// EnumDescriber is implemented by enums describing their values, in the order
+// of Values. The descriptions are emitted as x-enum-descriptions.
+type EnumDescriber interface {
+	EnumDescriptions() []string
+}
+
+// scalarType is the json type and format of a go kind.
+type scalarType struct {
+	_type  string
+	format string
+}
+
+// kindTypes describes the go kinds of scalar values.
+var kindTypes = map[reflect.Kind]scalarType{
+	reflect.Int:     {"integer", ""},
+	reflect.Int8:    {"integer", ""},
+	reflect.Int16:   {"integer", ""},
+	reflect.Int32:   {"integer", "int32"},
+	reflect.Int64:   {"integer", "int64"},
+	reflect.Uint:    {"integer", ""},
+	reflect.Uint8:   {"integer", ""},
+	reflect.Uint16:  {"integer", ""},
+	reflect.Uint32:  {"integer", "uint32"},
+	reflect.Uint64:  {"integer", "uint64"},
+	reflect.Float32: {"number", "float"},
+	reflect.Float64: {"number", "double"},
+	reflect.Bool:    {"boolean", ""},
+	reflect.String:  {"string", ""},
+}
+
+// kindType returns the json type of a go kind, empty if it has none.
+func kindType(kind reflect.Kind) string {
+	return kindTypes[kind]._type
+}
+
+// enumSchema returns the schema of an enum type. Its type is the one of the
+// underlying go type, or the one of the values for a struct, and every value
+// has to match it.
+func enumSchema(_type reflect.Type, values []any) (*openapi3.Schema, error) {
+	declared := kindType(_type.Kind())
+	if declared == "" {
+		for _, value := range values {
+			if value == nil {
+				continue
+			}
+			valueType := kindType(reflect.TypeOf(value).Kind())
+			if declared == "" || declared == "integer" && valueType == "number" {
+				declared = valueType
+			}
+		}
+	}
+	if declared == "" {
+		declared = "string"
+	}
+
+	var errs []error
+	schema := &openapi3.Schema{Type: &openapi3.Types{declared}}
+	for _, value := range values {
+		if value == nil {
+			schema.Enum = append(schema.Enum, nil)
+			continue
+		}
+		v := reflect.ValueOf(value)
+		valueType := kindType(v.Kind())
+		if valueType != declared && !(declared == "number" && valueType == "integer") {
+			errs = append(errs, fmt.Errorf("enum value %v is not of type %s", value, declared))
+			continue
+		}
+		switch {
+		case v.CanInt():
+			schema.Enum = append(schema.Enum, v.Int())
+		case v.CanUint():
+			schema.Enum = append(schema.Enum, v.Uint())
+		case v.CanFloat():
+			schema.Enum = append(schema.Enum, v.Float())
+		case v.Kind() == reflect.String:
+			schema.Enum = append(schema.Enum, v.String())
+		default:
+			schema.Enum = append(schema.Enum, v.Bool())
+		}
+	}
+
+	zero := reflect.New(_type).Elem().Interface()
+	if namer, ok := zero.(EnumNamer); ok {
+		names := namer.EnumNames()
+		if len(names) != len(values) {
+			errs = append(errs, fmt.Errorf("enum has %d values and %d names", len(values), len(names)))
+		}
+		schema.Extensions = map[string]any{"x-enum-varnames": names}
+	}
+	if describer, ok := zero.(EnumDescriber); ok {
+		descriptions := describer.EnumDescriptions()
+		if len(descriptions) != len(values) {
+			errs = append(errs, fmt.Errorf("enum has %d values and %d descriptions", len(values), len(descriptions)))
+		}
+		if schema.Extensions == nil {
+			schema.Extensions = make(map[string]any)
+		}
+		schema.Extensions["x-enum-descriptions"] = descriptions
+	}
+	return schema, errors.Join(errs...)
+}
@@ -0,0 +1,87 @@
+package openapigen
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type Priority int
+
+const (
+	Low Priority = iota
+	High
+)
+
+func (Priority) Values() []any              { return []any{Low, High} }
+func (Priority) EnumNames() []string        { return []string{"Low", "High"} }
+func (Priority) EnumDescriptions() []string { return []string{"when possible", "right now"} }
+
+type Ratio float64
+
+func (Ratio) Values() []any { return []any{0.5, 1, 2} }
+
+type HTTPCode struct{}
+
+func (HTTPCode) Values() []any { return []any{200, 404} }
+
+type Task struct {
+	Priority Priority `json:"priority"`
+	Ratio    Ratio    `json:"ratio"`
+	Code     HTTPCode `json:"code"`
+}
+
+func TestEnums(t *testing.T) {
+
+	newDoc := func() *Document {
+		return (&Document{}).Paths(
+			NewPath("/tasks").Post().
+				JSONBody(Task{}).
+				Responses(NewResponse(204).Description("ok")),
+		)
+	}
+
+	buffer := bytes.NewBuffer(nil)
+	err := newDoc().Write(buffer, 2)
+	fmt.Println(buffer.String())
+	require.NoError(t, err)
+	assert.Equal(t, testEnumsExpectedSpecs, strings.ReplaceAll(buffer.String(), "\n", ""))
+
+	// the values are described with oneOf in openapi 3.1
+	buffer.Reset()
+	err = newDoc().OpenAPIVersion(OpenAPI31).Write(buffer, 2)
+	require.NoError(t, err)
+	assert.Contains(t, buffer.String(), `
+    Priority:
+      oneOf:
+        - const: 0
+          description: when possible
+          title: Low
+        - const: 1
+          description: right now
+          title: High
+      type: integer
+`)
+}
+
+type Size string
+
+func (Size) Values() []any       { return []any{"S", "M", 42} }
+func (Size) EnumNames() []string { return []string{"Small"} }
+
+func TestEnumsErrors(t *testing.T) {
+
+	err := (&Document{}).
+		Paths(NewPath("/sizes").Get().Parameter(NewParameter("size").InQuery().Ref(Size("")))).
+		Build()
+	require.Error(t, err)
+
+	errs := splitErrors(err)
+	require.Len(t, errs, 2)
+	assert.EqualError(t, errs[0], "GET /sizes, type openapigen.Size: enum value 42 is not of type string")
+	assert.EqualError(t, errs[1], "GET /sizes, type openapigen.Size: enum has 3 values and 1 names")
+}
@@ -0,0 +1,96 @@
+package openapigen
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+)
+
+var (
+	ErrUnsupportedType = errors.New("type not supported")
+	ErrAnonymousStruct = errors.New("anonymous struct cannot be referenced")
+	ErrNameCollision   = errors.New("component name collision")
+)
+
+// BuildError describes a problem found while building a document, along with
+// the context (operation, Go type, struct field) in which it was found.
+type BuildError struct {
+	Path   string
+	Method string
+	Type   string
+	Field  string
+	Err    error
+}
+
+func (e *BuildError) Error() string {
+	var context []string
+	if operation := strings.TrimSpace(strings.ToUpper(e.Method) + " " + e.Path); operation != "" {
+		context = append(context, operation)
+	}
+	if e.Type != "" {
+		context = append(context, "type "+e.Type)
+	}
+	if e.Field != "" {
+		context = append(context, "field "+e.Field)
+	}
+	if len(context) == 0 {
+		return e.Err.Error()
+	}
+	return strings.Join(context, ", ") + ": " + e.Err.Error()
+}
+
+func (e *BuildError) Unwrap() error {
+	return e.Err
+}
+
+func typeError(_type reflect.Type, err error) error {
+	return &BuildError{Type: typeName(_type), Err: err}
+}
+
+func fieldError(_type reflect.Type, field string, err error) error {
+	var buildErr *BuildError
+	if errors.As(err, &buildErr) {
+		return err
+	}
+	return &BuildError{Type: typeName(_type), Field: field, Err: err}
+}
+
+// operationError attaches the operation context to err, keeping the
+// type and field context already recorded.
+func operationError(path, method string, err error) error {
+	buildErr, ok := err.(*BuildError)
+	if !ok {
+		return &BuildError{Path: path, Method: method, Err: err}
+	}
+	if buildErr.Path == "" {
+		buildErr.Path = path
+	}
+	if buildErr.Method == "" {
+		buildErr.Method = method
+	}
+	return buildErr
+}
+
+// splitErrors flattens errors built with errors.Join so each one can be
+// decorated and reported on its own.
+func splitErrors(err error) []error {
+	if err == nil {
+		return nil
+	}
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return []error{err}
+	}
+	var ret []error
+	for _, e := range joined.Unwrap() {
+		ret = append(ret, splitErrors(e)...)
+	}
+	return ret
+}
+
+func typeName(_type reflect.Type) string {
+	if _type == nil {
+		return "nil"
+	}
+	return _type.String()
+}
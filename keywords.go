@@ -0,0 +1,147 @@
+package openapigen
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+)
+
+var (
+	numberTypes = []string{"integer", "number"}
+	stringTypes = []string{"string"}
+	arrayTypes  = []string{"array"}
+	objectTypes = []string{"object"}
+)
+
+// keywordTypes lists the types of the properties each validation keyword of
+// the oapi tag applies to.
+var keywordTypes = map[string][]string{
+	"min":              numberTypes,
+	"max":              numberTypes,
+	"exclusiveMinimum": numberTypes,
+	"exclusiveMaximum": numberTypes,
+	"multipleOf":       numberTypes,
+	"minLength":        stringTypes,
+	"maxLength":        stringTypes,
+	"pattern":          stringTypes,
+	"minItems":         arrayTypes,
+	"maxItems":         arrayTypes,
+	"uniqueItems":      arrayTypes,
+	"minProperties":    objectTypes,
+	"maxProperties":    objectTypes,
+}
+
+// setKeywords parses the validation keywords of an oapi tag.
+func setKeywords(property *Property, tag oapiTag) error {
+	var errs []error
+	number := func(key string) *float64 {
+		value, ok := tag.lookup(key)
+		if !ok {
+			return nil
+		}
+		val, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %q is not a number", key, value))
+			return nil
+		}
+		return &val
+	}
+	unsigned := func(key string) *uint64 {
+		value, ok := tag.lookup(key)
+		if !ok {
+			return nil
+		}
+		val, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %q is not a positive integer", key, value))
+			return nil
+		}
+		return &val
+	}
+
+	property.minimum = number("min")
+	property.maximum = number("max")
+	property.multipleOf = number("multipleOf")
+	if property.multipleOf != nil && *property.multipleOf <= 0 {
+		errs = append(errs, fmt.Errorf("multipleOf: %v is not strictly positive", *property.multipleOf))
+	}
+	property.minLength = unsigned("minLength")
+	property.maxLength = unsigned("maxLength")
+	property.minItems = unsigned("minItems")
+	property.maxItems = unsigned("maxItems")
+	property.minProperties = unsigned("minProperties")
+	property.maxProperties = unsigned("maxProperties")
+	if value, ok := tag.lookup("pattern"); ok {
+		property.pattern = value
+	}
+
+	property.exclusiveMinimum = tag.flag("exclusiveMinimum")
+	property.exclusiveMaximum = tag.flag("exclusiveMaximum")
+	property.uniqueItems = tag.flag("uniqueItems")
+	property.readOnly = tag.flag("readOnly")
+	property.writeOnly = tag.flag("writeOnly")
+	if property.readOnly && property.writeOnly {
+		errs = append(errs, errors.New("a property cannot be both readOnly and writeOnly"))
+	}
+	return errors.Join(errs...)
+}
+
+// checkKeywords verifies the validation keywords of an oapi tag apply to the
+// type of the property, and parses its example according to this type.
+func checkKeywords(property *Property, tag oapiTag) error {
+	var errs []error
+	_type := property.jsonType()
+	for _, entry := range tag {
+		types, ok := keywordTypes[entry.key]
+		if ok && !slices.Contains(types, _type) {
+			errs = append(errs, fmt.Errorf("%s does not apply to %s", entry.raw, describeType(_type)))
+		}
+	}
+
+	if value, ok := tag.lookup("example"); ok {
+		example, err := parseExample(value, _type)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("example: %w", err))
+		}
+		property.example = example
+	}
+	return errors.Join(errs...)
+}
+
+func describeType(_type string) string {
+	if _type == "" {
+		return "a referenced schema"
+	}
+	return "a property of type " + _type
+}
+
+// parseExample converts the example of an oapi tag to the type of the property.
+func parseExample(value string, _type string) (any, error) {
+	switch _type {
+	case "integer":
+		return strconv.ParseInt(value, 10, 64)
+	case "number":
+		return strconv.ParseFloat(value, 64)
+	case "boolean":
+		return strconv.ParseBool(value)
+	case "string":
+		return value, nil
+	default:
+		return parseString(value), nil
+	}
+}
+
+// jsonType returns the type of the schema of a property, empty for a
+// reference.
+func (p *Property) jsonType() string {
+	switch {
+	case p.ref != "":
+		return ""
+	case p.itemsProp != nil:
+		return "array"
+	case p.additionalProperties != nil:
+		return "object"
+	}
+	return p._type
+}
@@ -0,0 +1,60 @@
+package openapigen
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type Signup struct {
+	Login    string            `json:"login" oapi:"title:Login,minLength:3,maxLength:20,pattern:^[a-z]+$,example:gopher"`
+	Password string            `json:"password" oapi:"writeOnly:true,minLength:12"`
+	ID       int               `json:"id" oapi:"readOnly:true,example:42"`
+	Age      int               `json:"age" oapi:"min:18,max:130,exclusiveMaximum:true"`
+	Weight   float64           `json:"weight" oapi:"multipleOf:0.5"`
+	Emails   []string          `json:"emails" oapi:"minItems:1,maxItems:3,uniqueItems:true"`
+	Labels   map[string]string `json:"labels" oapi:"minProperties:1,maxProperties:10"`
+}
+
+func TestKeywords(t *testing.T) {
+
+	doc := &Document{}
+	doc.
+		Paths(
+			NewPath("/signup").Post().
+				JSONBody(Signup{}).
+				Responses(NewResponse(204).Description("ok")),
+		)
+
+	buffer := bytes.NewBuffer(nil)
+	err := doc.Write(buffer, 2)
+	fmt.Println(buffer.String())
+	require.NoError(t, err)
+	assert.Equal(t, testKeywordsExpectedSpecs, strings.ReplaceAll(buffer.String(), "\n", ""))
+}
+
+type InvalidKeywords struct {
+	Name  string   `json:"name" oapi:"min:1"`
+	Count int      `json:"count" oapi:"minLength:x"`
+	Tags  []string `json:"tags" oapi:"uniqueItems:true,pattern:^a"`
+	Code  int      `json:"code" oapi:"example:abc"`
+	Kid   Kid      `json:"kid" oapi:"maxProperties:2"`
+}
+
+func TestKeywordsErrors(t *testing.T) {
+
+	_, _, err := Properties(InvalidKeywords{})
+	require.Error(t, err)
+
+	errs := splitErrors(err)
+	require.Len(t, errs, 5)
+	assert.EqualError(t, errs[0], "type openapigen.InvalidKeywords, field Name: min:1 does not apply to a property of type string")
+	assert.EqualError(t, errs[1], `type openapigen.InvalidKeywords, field Count: minLength: "x" is not a positive integer`)
+	assert.EqualError(t, errs[2], "type openapigen.InvalidKeywords, field Tags: pattern:^a does not apply to a property of type array")
+	assert.Contains(t, errs[3].Error(), "field Code: example: ")
+	assert.EqualError(t, errs[4], "type openapigen.InvalidKeywords, field Kid: maxProperties:2 does not apply to a referenced schema")
+}
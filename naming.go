@@ -0,0 +1,197 @@
+package openapigen
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/fmarmol/openapigen/utils"
+)
+
+// NamingStrategy turns the name of a go struct field into a property name.
+type NamingStrategy func(fieldName string) string
+
+var (
+	SnakeCase NamingStrategy = ToSnakeCase
+	CamelCase NamingStrategy = ToCamelCase
+	GoName    NamingStrategy = func(fieldName string) string { return fieldName }
+)
+
+func ToCamelCase(str string) string {
+	parts := strings.Split(ToSnakeCase(str), "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// NamingPolicy describes how struct fields are turned into properties.
+// The zero value follows encoding/json: the json tag gives the name of the
+// property and `json:"-"` omits it. Fields without json tag are named with the
+// Fallback strategy (snake_case by default). The oapi tag always takes
+// precedence.
+//
+// RequiredWithoutOmitEmpty marks as required the fields tagged without
+// omitempty, encoding/json always writes them. It suits the documents
+// describing responses, a decoded request never requires a field.
+type NamingPolicy struct {
+	IgnoreJSONTags           bool
+	RequiredWithoutOmitEmpty bool
+	Fallback                 NamingStrategy
+}
+
+type jsonField struct {
+	name      string
+	named     bool
+	tagged    bool
+	omitEmpty bool
+	skip      bool
+}
+
+func (n NamingPolicy) fallback(fieldName string) string {
+	if n.Fallback == nil {
+		return SnakeCase(fieldName)
+	}
+	return n.Fallback(fieldName)
+}
+
+func (n NamingPolicy) field(field reflect.StructField) jsonField {
+	ret := jsonField{name: n.fallback(field.Name)}
+	if n.IgnoreJSONTags {
+		return ret
+	}
+	tag, ok := field.Tag.Lookup("json")
+	if !ok {
+		return ret
+	}
+	if tag == "-" {
+		ret.skip = true
+		return ret
+	}
+	ret.tagged = true
+	name, options, _ := strings.Cut(tag, ",")
+	if name != "" {
+		ret.name = name
+		ret.named = true
+	}
+	for _, option := range strings.Split(options, ",") {
+		if option == "omitempty" || option == "omitzero" {
+			ret.omitEmpty = true
+		}
+	}
+	return ret
+}
+
+// required reports whether a field is required according to its json tag,
+// encoding/json always emits fields tagged without omitempty.
+func (n NamingPolicy) required(field jsonField) bool {
+	return n.RequiredWithoutOmitEmpty && field.tagged && !field.omitEmpty
+}
+
+// SchemaNamer is implemented by types choosing the name of their component
+// schema.
+type SchemaNamer interface {
+	SchemaName() string
+}
+
+// NameCollisionStrategy returns the component name of a go type whose name is
+// already used by another type. Returning an empty name reports the collision
+// as an error.
+type NameCollisionStrategy func(_type reflect.Type, name string) string
+
+// PackagePrefix prefixes the name with the package of the type, the Account
+// type of a billing package is named BillingAccount.
+func PackagePrefix(_type reflect.Type, name string) string {
+	for _type.PkgPath() == "" {
+		switch _type.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+			_type = _type.Elem()
+			continue
+		}
+		return ""
+	}
+	pkg := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, path.Base(_type.PkgPath()))
+	if pkg == "" {
+		return ""
+	}
+	return strings.ToUpper(pkg[:1]) + pkg[1:] + name
+}
+
+// schemaKey identifies what is behind a component schema: its go type, or its
+// composition for a polymorphic schema.
+func schemaKey(s *Schema) any {
+	if poly, ok := s.object.(*Polymorphic); ok {
+		return fmt.Sprintf("%s%v", poly.composition, utils.Map(poly.objects, reflect.TypeOf))
+	}
+	return derefType(reflect.TypeOf(s.object))
+}
+
+// sameSchema reports whether two schema keys are described by the same
+// schema, like []Person and a named type Persons []Person.
+func sameSchema(a, b any) bool {
+	typeA, okA := a.(reflect.Type)
+	typeB, okB := b.(reflect.Type)
+	if !okA || !okB || typeA.Kind() != typeB.Kind() {
+		return false
+	}
+	switch typeA.Kind() {
+	case reflect.Slice:
+		return typeA.Elem() == typeB.Elem()
+	case reflect.Array:
+		return typeA.Elem() == typeB.Elem() && typeA.Len() == typeB.Len()
+	}
+	return false
+}
+
+// schemaName returns the component name of a schema. The name of a go type is
+// kept for the whole document, another type with the same name is renamed by
+// the collision strategy or reported as an error.
+func (r *reflector) schemaName(s *Schema) (string, error) {
+	key := schemaKey(s)
+	if name, ok := r.schemaNames[key]; ok {
+		return name, nil
+	}
+	name := s.ObjectName()
+	if name == "" {
+		return "", s.unnamedError()
+	}
+	if r.components == nil {
+		r.components = make(map[string]any)
+		r.schemaNames = make(map[any]string)
+	}
+
+	var err error
+	if other, ok := r.components[name]; ok && !sameSchema(other, key) {
+		var newName string
+		if _type, isType := key.(reflect.Type); isType && r.collisions != nil {
+			newName = r.collisions(_type, name)
+		}
+		if _, taken := r.components[newName]; newName == "" || taken {
+			err = fmt.Errorf("%w: %s is used by both %v and %v", ErrNameCollision, name, other, key)
+		} else {
+			name = newName
+		}
+	}
+	if err == nil {
+		r.components[name] = key
+	}
+	r.schemaNames[key] = name
+	return name, err
+}
+
+func (r *reflector) refPath(s *Schema) (string, error) {
+	name, err := r.schemaName(s)
+	if name == "" {
+		return "", err
+	}
+	return fmt.Sprintf("#/components/schemas/%s", name), err
+}
@@ -1,24 +1,42 @@
 package openapigen
 
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/fmarmol/kin-openapi/openapi3"
+)
+
 type Pin string
 
 const (
 	PATH   Pin = "path"
 	QUERY  Pin = "query"
 	HEADER Pin = "header"
+	COOKIE Pin = "cookie"
 )
 
 type Parameter struct {
-	isComponent   bool
-	componentName string
-	name          string
-	in            Pin
-	_type         string
-	format        string
-	required      bool
-	ref           any
-	enums         Enum
-	min, max      *float64
+	isComponent     bool
+	componentName   string
+	name            string
+	in              Pin
+	_type           string
+	format          string
+	required        bool
+	ref             any
+	enums           Enum
+	min, max        *float64
+	description     string
+	deprecated      bool
+	style           string
+	explode         *bool
+	allowReserved   bool
+	allowEmptyValue bool
+	example         any
 }
 
 func NewParameter(name string) *Parameter {
@@ -50,6 +68,10 @@ func (p *Parameter) InQuery() *Parameter {
 	return p.In(QUERY)
 }
 
+func (p *Parameter) InCookie() *Parameter {
+	return p.In(COOKIE)
+}
+
 func (p *Parameter) In(v Pin) *Parameter {
 	p.in = v
 	return p
@@ -88,3 +110,171 @@ func (p *Parameter) Max(v float64) *Parameter {
 	p.max = &v
 	return p
 }
+
+func (p *Parameter) Description(v string) *Parameter {
+	p.description = v
+	return p
+}
+
+func (p *Parameter) Deprecated() *Parameter {
+	p.deprecated = true
+	return p
+}
+
+// Style sets how the value is serialized, like "form" or "deepObject" for a
+// query parameter. The styles are defined by openapi3.SerializationForm and
+// the other serialization constants.
+func (p *Parameter) Style(v string) *Parameter {
+	p.style = v
+	return p
+}
+
+// Explode sets whether arrays and objects are serialized as separate
+// parameters, like ?id=1&id=2 instead of ?id=1,2.
+func (p *Parameter) Explode(v bool) *Parameter {
+	p.explode = &v
+	return p
+}
+
+// AllowReserved lets the value of a query parameter contain the reserved
+// characters of RFC 3986 without percent-encoding them.
+func (p *Parameter) AllowReserved() *Parameter {
+	p.allowReserved = true
+	return p
+}
+
+// AllowEmptyValue lets a query parameter be sent with an empty value.
+func (p *Parameter) AllowEmptyValue() *Parameter {
+	p.allowEmptyValue = true
+	return p
+}
+
+func (p *Parameter) Example(v any) *Parameter {
+	p.example = v
+	return p
+}
+
+// parameterStyles lists the serialization styles allowed in each location.
+var parameterStyles = map[Pin][]string{
+	PATH:   {openapi3.SerializationSimple, openapi3.SerializationLabel, openapi3.SerializationMatrix},
+	QUERY:  {openapi3.SerializationForm, openapi3.SerializationSpaceDelimited, openapi3.SerializationPipeDelimited, openapi3.SerializationDeepObject},
+	HEADER: {openapi3.SerializationSimple},
+	COOKIE: {openapi3.SerializationForm},
+}
+
+// checkSerialization verifies the serialization options of a parameter are
+// allowed in its location.
+func (p *Parameter) checkSerialization() error {
+	var errs []error
+	if styles, ok := parameterStyles[p.in]; ok && p.style != "" && !slices.Contains(styles, p.style) {
+		errs = append(errs, fmt.Errorf("style %s is not allowed in %s, expected one of %v", p.style, p.in, styles))
+	}
+	if p.style == openapi3.SerializationDeepObject && p.explode != nil && !*p.explode {
+		errs = append(errs, errors.New("style deepObject requires explode"))
+	}
+	if p.allowReserved && p.in != QUERY {
+		errs = append(errs, fmt.Errorf("allowReserved does not apply to a %s parameter", p.in))
+	}
+	if p.allowEmptyValue && p.in != QUERY {
+		errs = append(errs, fmt.Errorf("allowEmptyValue does not apply to a %s parameter", p.in))
+	}
+	return errors.Join(errs...)
+}
+
+// parameterTags are the struct tags giving the location and the name of the
+// parameters declared by ParametersFrom, like `query:"page"`.
+var parameterTags = []Pin{PATH, QUERY, HEADER, COOKIE}
+
+// ParametersFrom declares a parameter for each field of a struct tagged with
+// its location and its name: `path:"id"`, `query:"page"`, `header:"X-Request-ID"`
+// or `cookie:"session"`. Fields are described like the properties of a body,
+// path parameters are always required.
+func (p *Path) ParametersFrom(obj any) *Path {
+	p.paramStructs = append(p.paramStructs, obj)
+	return p
+}
+
+func (p *Path) buildStructParameters(refl *reflector, obj any) {
+	_type := reflect.TypeOf(obj)
+	if _type != nil && _type.Kind() == reflect.Pointer {
+		_type = _type.Elem()
+	}
+	if _type == nil || _type.Kind() != reflect.Struct {
+		p.addError(typeError(_type, fmt.Errorf("%w: parameters are declared by a struct", ErrUnsupportedType)))
+		return
+	}
+	p.structParameters(refl, _type)
+}
+
+// structParameters declares the parameters of the tagged fields of a struct,
+// untagged embedded structs are flattened.
+func (p *Path) structParameters(refl *reflector, _type reflect.Type, parents ...reflect.Type) {
+	for i := range _type.NumField() {
+		field := _type.Field(i)
+		if field.Type.Kind() == reflect.Pointer {
+			field.Type = field.Type.Elem()
+		}
+		in, name, err := parameterLocation(field)
+		if err != nil {
+			p.addError(fieldError(_type, field.Name, err))
+			continue
+		}
+		if in == "" {
+			if field.Anonymous && field.Type.Kind() == reflect.Struct && !slices.Contains(parents, field.Type) && field.Type != _type {
+				p.structParameters(refl, field.Type, append(parents, _type)...)
+			}
+			continue
+		}
+		if !field.IsExported() || name == "-" {
+			continue
+		}
+
+		tag, err := parseTag(field.Tag.Get("oapi"))
+		if err != nil {
+			p.addError(fieldError(_type, field.Name, fmt.Errorf("oapi tag: %w", err)))
+			continue
+		}
+		property := Property{name: refl.naming.fallback(field.Name), description: refl.comments.fieldDoc(_type, field.Name)}
+		newSchemas, err := refl.fieldProperty(&property, nil, field, tag)
+		for _, s := range newSchemas {
+			p.registerSchema(refl, s)
+		}
+		if err != nil {
+			p.addError(fieldError(_type, field.Name, err))
+			continue
+		}
+		if name != "" {
+			property.name = name
+		}
+
+		oapiParam := &openapi3.Parameter{
+			In:          string(in),
+			Name:        property.name,
+			Description: property.description,
+			Deprecated:  property.deprecated,
+			Required:    property.required || in == PATH,
+		}
+		property.description, property.deprecated = "", false
+		oapiParam.Schema = oapiSchemaFromProperty(&property)
+		p.parameters = append(p.parameters, &openapi3.ParameterRef{Value: oapiParam})
+	}
+}
+
+// parameterLocation returns the location and the name of the parameter
+// declared by a struct field, the location is empty for an untagged field.
+func parameterLocation(field reflect.StructField) (Pin, string, error) {
+	var in Pin
+	var name string
+	for _, pin := range parameterTags {
+		value, ok := field.Tag.Lookup(string(pin))
+		if !ok {
+			continue
+		}
+		if in != "" {
+			return "", "", fmt.Errorf("parameter is tagged both in %s and in %s", in, pin)
+		}
+		in = pin
+		name, _, _ = strings.Cut(value, ",")
+	}
+	return in, name, nil
+}
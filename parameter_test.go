@@ -0,0 +1,126 @@
+package openapigen
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/fmarmol/kin-openapi/openapi3"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type Pagination struct {
+	Page    int `query:"page" oapi:"min:1,default:1"`
+	PerPage int `query:"per_page" validate:"max=100"`
+}
+
+type ListMoviesQuery struct {
+	Pagination
+	StudioID  uuid.UUID `path:"studio_id"`
+	Genre     Priority  `query:"genre" oapi:"description:genre of the movies"`
+	Sort      []string  `query:"sort,omitempty" oapi:"uniqueItems"`
+	Before    *string   `query:"before" oapi:"format:date,deprecated"`
+	RequestID string    `header:"X-Request-ID" oapi:"required,format:uuid"`
+	Session   string    `cookie:"session"`
+	Ignored   string    `query:"-"`
+	internal  string    `query:"internal"`
+	Untagged  string
+}
+
+func TestParametersFrom(t *testing.T) {
+
+	doc := &Document{}
+	doc.
+		ValidationTags("validate").
+		Paths(
+			NewPath("/studios/{studio_id}/movies").Get().
+				ParametersFrom(ListMoviesQuery{}).
+				Responses(NewResponse(204).Description("ok")),
+		)
+
+	buffer := bytes.NewBuffer(nil)
+	err := doc.Write(buffer, 2)
+	fmt.Println(buffer.String())
+	require.NoError(t, err)
+	assert.Equal(t, testParametersFromExpectedSpecs, strings.ReplaceAll(buffer.String(), "\n", ""))
+}
+
+type InvalidParameters struct {
+	ID    string `path:"id" query:"id"`
+	Count int    `query:"count" oapi:"minLength:1"`
+}
+
+func TestParametersFromErrors(t *testing.T) {
+
+	doc := &Document{}
+	doc.
+		Paths(
+			NewPath("/items").Get().
+				ParametersFrom(InvalidParameters{}).
+				ParametersFrom("page").
+				Responses(NewResponse(204).Description("ok")),
+		)
+
+	err := doc.Build()
+	require.Error(t, err)
+
+	errs := splitErrors(err)
+	require.Len(t, errs, 3)
+	assert.EqualError(t, errs[0], "GET /items, type openapigen.InvalidParameters, field ID: parameter is tagged both in path and in query")
+	assert.EqualError(t, errs[1], "GET /items, type openapigen.InvalidParameters, field Count: minLength:1 does not apply to a property of type integer")
+	assert.EqualError(t, errs[2], "GET /items, type string: type not supported: parameters are declared by a struct")
+}
+
+type MovieFilter struct {
+	Status string `json:"status,omitempty"`
+	Year   int    `json:"year,omitempty"`
+}
+
+func TestParameterOptions(t *testing.T) {
+
+	doc := &Document{}
+	doc.
+		Paths(
+			NewPath("/movies").Get().
+				Parameter(NewParameter("filter").InQuery().Ref(MovieFilter{}).Style(openapi3.SerializationDeepObject).Explode(true)).
+				Parameter(NewParameter("ids").InQuery().Ref([]int{}).Explode(false).Description("comma separated identifiers")).
+				Parameter(NewParameter("tags").InQuery().Ref([]string{}).Style(openapi3.SerializationPipeDelimited)).
+				Parameter(NewParameter("redirect").InQuery().Type("string").AllowReserved().Example("/movies?page=2")).
+				Parameter(NewParameter("q").InQuery().Type("string").AllowEmptyValue()).
+				Parameter(NewParameter("session").InCookie().Type("string").Required()).
+				Parameter(NewParameter("X-Api-Version").InHeader().Type("integer").Deprecated()).
+				Responses(NewResponse(204).Description("ok")),
+		)
+
+	buffer := bytes.NewBuffer(nil)
+	err := doc.Write(buffer, 2)
+	fmt.Println(buffer.String())
+	require.NoError(t, err)
+	assert.Equal(t, testParameterOptionsExpectedSpecs, strings.ReplaceAll(buffer.String(), "\n", ""))
+}
+
+func TestParameterOptionsErrors(t *testing.T) {
+
+	doc := &Document{}
+	doc.
+		Paths(
+			NewPath("/movies/{id}").Get().
+				Parameter(NewParameter("id").InPath().Type("integer").Required().Style(openapi3.SerializationForm).AllowReserved()).
+				Parameter(NewParameter("filter").InQuery().Ref(MovieFilter{}).Style(openapi3.SerializationDeepObject).Explode(false)).
+				Parameter(NewParameter("session").InCookie().Type("string").AllowEmptyValue()).
+				Responses(NewResponse(204).Description("ok")),
+		)
+
+	err := doc.Build()
+	require.Error(t, err)
+
+	errs := splitErrors(err)
+	require.Len(t, errs, 4)
+	assert.EqualError(t, errs[0], "GET /movies/{id}: parameter id: style form is not allowed in path, expected one of [simple label matrix]")
+	assert.EqualError(t, errs[1], "GET /movies/{id}: parameter id: allowReserved does not apply to a path parameter")
+	assert.EqualError(t, errs[2], "GET /movies/{id}: parameter filter: style deepObject requires explode")
+	assert.EqualError(t, errs[3], "GET /movies/{id}: parameter session: allowEmptyValue does not apply to a cookie parameter")
+}
@@ -1,9 +1,11 @@
 package openapigen
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
 	"reflect"
+	"slices"
 
 	"github.com/fmarmol/kin-openapi/openapi3"
 )
@@ -15,50 +17,96 @@ func NewComponentParameter(name string, param Parameter) Parameter {
 }
 
 type Path struct {
-	path                string
-	method              string
-	tags                []string
-	summary             string
-	description         string
-	operationID         string
-	parameters          []*openapi3.ParameterRef
-	responses           []*Response
-	apiResponses        map[string]*openapi3.ResponseRef
-	apiSchemas          map[string]*openapi3.SchemaRef
-	componentParameters map[string]*openapi3.ParameterRef
-	content             string
-	ref                 *Schema
-	inline              []byte // WARNING: this only a temp fix to have a custom request body inline, openapi3.Response (only json) (not a ref)
-	defaultResponse     *Response
-	contentRequired     bool
+	path            string
+	method          string
+	tags            []string
+	summary         string
+	description     string
+	operationID     string
+	params          []*Parameter
+	paramStructs    []any // structs declaring parameters, see ParametersFrom
+	parameters      []*openapi3.ParameterRef
+	responses       []*Response
+	apiResponses    map[string]*openapi3.ResponseRef
+	apiSchemas      map[string]*openapi3.SchemaRef
+	components      components // components declared by the operation
+	body            *RequestBody
+	requestBody     *openapi3.RequestBodyRef
+	defaultResponse *Response
+	security        *openapi3.SecurityRequirements // nil inherits the requirements of the document
+	errs            []error                        // errors found while declaring the path
+	buildErrs       []error                        // errors found while building the path
+	refl            *reflector                     // reflector of the last build
 }
 
 func NewPath(path string) *Path {
 	p := new(Path)
 	p.path = path
+	return p
+}
+
+// build turns the declaration of the path into openapi objects, using the
+// options of the document it belongs to.
+func (p *Path) build(refl *reflector) error {
+	p.refl = refl
+	p.parameters = nil
+	p.requestBody = nil
 	p.apiResponses = make(map[string]*openapi3.ResponseRef)
 	p.apiSchemas = make(map[string]*openapi3.SchemaRef)
-	p.componentParameters = make(map[string]*openapi3.ParameterRef)
-	return p
+	p.components = newComponents()
+	p.buildErrs = nil
+
+	for _, param := range p.params {
+		p.buildParameter(refl, param)
+	}
+	for _, obj := range p.paramStructs {
+		p.buildStructParameters(refl, obj)
+	}
+	if p.body != nil {
+		p.requestBody = p.buildRequestBody(refl, p.body)
+	}
+	for _, r := range p.responses {
+		p.buildResponse(refl, r)
+	}
+	p.setDefaultResponse(refl)
+
+	return errors.Join(append(slices.Clone(p.errs), p.buildErrs...)...)
 }
 
+// Content adds a content type to the request body, described by the type of
+// obj. A body can have several content types, like JSON and multipart.
 func (p *Path) Content(obj any, content string, required ...bool) *Path {
-	p.ref = NewSchema(obj)
-	p.content = content
+	return p.MediaType(content, NewMediaType(obj), required...)
+}
+
+// MediaType adds a content type to the request body, with its examples and
+// its encoding.
+func (p *Path) MediaType(content string, m *MediaType, required ...bool) *Path {
+	if p.body == nil {
+		p.body = NewRequestBody()
+	}
+	// a body completed by the path no longer matches its component, it is
+	// described in place
+	p.body.componentName = ""
+	p.body.MediaType(content, m)
 
 	if len(required) > 0 && required[0] {
-		p.contentRequired = true
+		p.body.required = true
 	}
-	p.registerSchema(p.ref)
 	return p
 }
 
-func (p *Path) Inline(data map[string]any) *Path {
-	raw, err := json.Marshal(data)
-	if err != nil {
-		panic(err)
+// RequestBody sets the body of the request, like a body declared as a
+// component. The body is copied, the contents added later by the path are
+// not shared with the other paths using it.
+func (p *Path) RequestBody(b *RequestBody) *Path {
+	if b == nil {
+		p.errs = append(p.errs, errors.New("request body is nil"))
+		return p
 	}
-	p.inline = raw
+	body := *b
+	body.contents = b.contents.clone()
+	p.body = &body
 	return p
 }
 
@@ -66,40 +114,30 @@ func (p *Path) JSONBody(obj any, required ...bool) *Path {
 	return p.Content(obj, "application/json", required...)
 }
 
+// JSONBodyInline describes the object in the request body instead of
+// referencing the component of its type.
+func (p *Path) JSONBodyInline(obj any, required ...bool) *Path {
+	return p.MediaType("application/json", NewMediaType(obj).Inline(), required...)
+}
+
 func (p *Path) FormData(obj any, required ...bool) *Path {
 	return p.Content(obj, "multipart/form-data", required...)
 }
 
 func (p *Path) Parameter(param *Parameter) *Path {
+	p.params = append(p.params, param)
+	return p
+}
+
+func (p *Path) buildParameter(refl *reflector, param *Parameter) {
 	var schemaRef *openapi3.SchemaRef
 
 	if param.ref != nil {
-		if t, ok := isSlice(param.ref); ok {
-			var itemsSchema *openapi3.SchemaRef
-			prop, isStruct := typeToProp(t)
-			if !isStruct {
-				itemsSchema = &openapi3.SchemaRef{
-					Value: &openapi3.Schema{
-						Type:   &openapi3.Types{prop._type},
-						Format: prop.format,
-						Enum:   prop.enums,
-					},
-				}
-			} else {
-				schema := NewSchema(reflect.Zero(t).Interface())
-				p.registerSchema(schema)
-				itemsSchema = &openapi3.SchemaRef{Ref: schema.RefPath()}
-			}
-			schemaRef = &openapi3.SchemaRef{
-				Value: &openapi3.Schema{
-					Type:  &openapi3.Types{"array"},
-					Items: itemsSchema,
-				},
-			}
-		} else {
-			schema := NewSchema(param.ref)
-			p.registerSchema(schema)
-			schemaRef = &openapi3.SchemaRef{Ref: schema.RefPath()}
+		var err error
+		schemaRef, err = p.typeSchemaRef(refl, reflect.TypeOf(param.ref), false)
+		if err != nil {
+			p.addError(fmt.Errorf("parameter %s: %w", param.name, err))
+			return
 		}
 	} else {
 		schemaRef = &openapi3.SchemaRef{
@@ -115,22 +153,36 @@ func (p *Path) Parameter(param *Parameter) *Path {
 		}
 	}
 
+	for _, err := range splitErrors(param.checkSerialization()) {
+		p.addError(fmt.Errorf("parameter %s: %w", param.name, err))
+	}
+
 	paramRef := &openapi3.ParameterRef{}
 	oapiParam := &openapi3.Parameter{
-		In:       string(param.in),
-		Name:     param.name,
-		Required: param.required,
-		Schema:   schemaRef,
+		In:              string(param.in),
+		Name:            param.name,
+		Description:     param.description,
+		Deprecated:      param.deprecated,
+		Required:        param.required,
+		Style:           param.style,
+		Explode:         param.explode,
+		AllowReserved:   param.allowReserved,
+		AllowEmptyValue: param.allowEmptyValue,
+		Schema:          schemaRef,
+		Example:         param.example,
 	}
 	if param.isComponent {
+		ref, err := param.RefPath()
+		if err != nil {
+			p.addError(err)
+			return
+		}
 		p.registerParameter(param, oapiParam)
-		paramRef.Ref = param.RefPath()
+		paramRef.Ref = ref
 	} else {
 		paramRef.Value = oapiParam
 	}
 	p.parameters = append(p.parameters, paramRef)
-
-	return p
 }
 
 func (p *Path) Method(m string) *Path {
@@ -193,62 +245,245 @@ func (p *Path) Responses(rs ...*Response) *Path {
 	return p
 }
 
-func (p *Path) registerSchema(s *Schema) {
-	value := openapi3.NewObjectSchema()
+func (p *Path) addError(err error) {
+	p.buildErrs = append(p.buildErrs, splitErrors(err)...)
+}
 
-	reflectValue := reflect.ValueOf(s.object)
+// refPath returns the reference of a schema, recording the error on the path
+// when the schema cannot be referenced.
+func (p *Path) refPath(refl *reflector, s *Schema) string {
+	ref, err := refl.refPath(s)
+	if err != nil {
+		p.addError(err)
+	}
+	return ref
+}
+
+func (p *Path) registerSchema(refl *reflector, s *Schema) {
+	_type := reflect.TypeOf(s.object)
+	if _type == nil {
+		p.addError(errors.New("cannot register a schema for a nil object"))
+		return
+	}
+	// an anonymous struct is reported where it is referenced
+	name, err := refl.schemaName(s)
+	if name == "" {
+		return
+	}
+	if err != nil {
+		p.addError(err)
+	}
+	if s.array {
+		p.registerArray(refl, s)
+		return
+	}
+	if p.registered(refl, name) {
+		return
+	}
+	if poly, ok := s.object.(*Polymorphic); ok {
+		p.setSchema(refl, name, p.polymorphicSchema(refl, name, poly))
+		return
+	}
 
-	if reflectValue.Type().Implements(_selfExtentionsImpl) {
-		method, ok := reflectValue.Type().MethodByName("SelfExtensions")
+	var extensions map[string]any
+	if _type.Implements(_selfExtentionsImpl) {
+		method, ok := _type.MethodByName("SelfExtensions")
 		if !ok {
-			panic("no")
+			p.addError(typeError(_type, errors.New("not a self extension")))
+			return
 		}
-		dst := reflect.New(reflectValue.Type()).Elem()
-		values := method.Func.Call([]reflect.Value{dst})
+		values := method.Func.Call([]reflect.Value{reflect.New(_type).Elem()})
 		if len(values) != 1 {
-			panic("Values() method should return a map, 0 found")
+			p.addError(typeError(_type, errors.New("SelfExtensions() method should return a map")))
+			return
 		}
 		_extensions, ok := values[0].Interface().(map[string]any)
 		if !ok {
-			panic("extensions type cannot be converted into map[string]any")
+			p.addError(typeError(_type, errors.New("extensions type cannot be converted into map[string]any")))
+			return
 		}
-		value.Extensions = _extensions
+		extensions = _extensions
 	}
 
-	if s.enums != nil {
-		value.Enum = s.enums
-		value.Type = &openapi3.Types{"string"} // TODO support other type
-		p.apiSchemas[s.ObjectName()] = openapi3.NewSchemaRef("", value)
-		return
+	enums := s.enums
+	if enums == nil && _type.Implements(_enumImpl) {
+		if enums, err = enumValues(_type); err != nil {
+			p.addError(typeError(_type, err))
+		}
 	}
-	if s.array {
-		// fmt.Printf("NEW OBJ: %+#v\n", s.object)
-
-		value.Type = &openapi3.Types{"array"}
-		value.Items = &openapi3.SchemaRef{
-			Ref: s.RefPath(),
+	if enums != nil {
+		value, err := enumSchema(_type, enums)
+		for _, err := range splitErrors(err) {
+			p.addError(typeError(_type, err))
 		}
-		p.apiSchemas[s.owner.ObjectName()] = openapi3.NewSchemaRef("", value)
-		p.registerSchema(NewSchema(s.object)) // need to register the child
+		if extensions != nil {
+			value.Extensions = mergeExtensions(value.Extensions, extensions)
+		}
+		value.Description = refl.comments.typeDoc(_type)
+		p.setSchema(refl, name, value)
 		return
 	}
 
-	properties, newSchemas := s.Properties()
+	properties, newSchemas, err := refl.properties(s.object)
+	if err != nil {
+		p.addError(err)
+	}
+
+	value := objectSchema(properties)
+	value.Extensions = extensions
+	value.Description = refl.comments.typeDoc(_type)
+	p.setSchema(refl, name, value)
+	for _, s := range newSchemas {
+		p.registerSchema(refl, s)
+	}
+
+}
 
-	value.Properties = make(openapi3.Schemas)
+// schemaRef returns a reference to the component of a schema, or the schema
+// itself when it is inlined. The components it uses are registered.
+func (p *Path) schemaRef(refl *reflector, s *Schema) *openapi3.SchemaRef {
+	if !s.inlined() && !refl.isRegistered(s) {
+		p.registerSchema(refl, s)
+		return &openapi3.SchemaRef{Ref: p.refPath(refl, s)}
+	}
+	if poly, ok := s.object.(*Polymorphic); ok {
+		return openapi3.NewSchemaRef("", p.polymorphicSchema(refl, poly.objectName(), poly))
+	}
+	_type := reflect.TypeOf(s.object)
+	schema, err := p.typeSchemaRef(refl, _type, s.inline)
+	if err != nil {
+		p.addError(fieldError(_type, "", err))
+	}
+	return schema
+}
+
+// typeSchemaRef returns the schema of a go type the way it is described in a
+// struct field, structs are referenced unless they are inlined. The components
+// it uses are registered.
+func (p *Path) typeSchemaRef(refl *reflector, _type reflect.Type, inline bool) (*openapi3.SchemaRef, error) {
+	property := &Property{inline: inline}
+	newSchemas, _, err := refl.setProperty(property, nil, _type)
+	for _, s := range newSchemas {
+		p.registerSchema(refl, s)
+	}
+	return oapiSchemaFromProperty(property), err
+}
+
+// objectSchema returns the schema of a struct from its properties, embedded
+// structs composed with allOf are referenced next to its own properties.
+func objectSchema(properties []Property) *openapi3.Schema {
+	var allOf openapi3.SchemaRefs
+	value := openapi3.NewObjectSchema()
 	for _, property := range properties {
+		if property.allOf {
+			allOf = append(allOf, &openapi3.SchemaRef{Ref: property.ref})
+			continue
+		}
 		if property.required {
 			value.Required = append(value.Required, property.name)
 		}
-
 		value.Properties[property.name] = oapiSchemaFromProperty(&property)
+	}
+	if len(allOf) > 0 {
+		return &openapi3.Schema{AllOf: append(allOf, openapi3.NewSchemaRef("", value))}
+	}
+	return value
+}
+
+// polymorphicSchema returns the oneOf or anyOf schema of poly, its types are
+// registered as components.
+func (p *Path) polymorphicSchema(refl *reflector, name string, poly *Polymorphic) *openapi3.Schema {
+	refs := openapi3.SchemaRefs{}
+	refsByType := make(map[reflect.Type]string)
+	for _, object := range poly.objects {
+		schema := NewSchema(object)
+		ref, err := refl.refPath(schema)
+		if err != nil {
+			p.addError(err)
+			continue
+		}
+		refs = append(refs, &openapi3.SchemaRef{Ref: ref})
+		refsByType[reflect.TypeOf(object)] = ref
+		p.registerSchema(refl, schema)
+	}
 
+	value := &openapi3.Schema{}
+	switch poly.composition {
+	case oneOf:
+		value.OneOf = refs
+	case anyOf:
+		value.AnyOf = refs
 	}
-	p.apiSchemas[s.ObjectName()] = openapi3.NewSchemaRef("", value)
-	for _, s := range newSchemas {
-		p.registerSchema(s)
+
+	if poly.discriminator != "" {
+		mapping := make(openapi3.StringMap)
+		for _, key := range slices.Sorted(maps.Keys(poly.mapping)) {
+			object := poly.mapping[key]
+			if ref, ok := object.(string); ok {
+				mapping[key] = ref
+				continue
+			}
+			ref, ok := refsByType[reflect.TypeOf(object)]
+			if !ok {
+				p.addError(fmt.Errorf("schema %s: discriminator value %q maps to %v which is not one of its types", name, key, reflect.TypeOf(object)))
+				continue
+			}
+			mapping[key] = ref
+		}
+		for _, object := range poly.objects {
+			properties, _, _ := refl.properties(object)
+			if !slices.ContainsFunc(properties, func(property Property) bool { return property.name == poly.discriminator }) {
+				p.addError(typeError(reflect.TypeOf(object), fmt.Errorf("schema %s: discriminator property %q not found", name, poly.discriminator)))
+			}
+		}
+		value.Discriminator = &openapi3.Discriminator{PropertyName: poly.discriminator, Mapping: mapping}
 	}
+	return value
+}
 
+// registerArray replaces the schema of a slice type being registered by an
+// array of its items, s is its items.
+func (p *Path) registerArray(refl *reflector, s *Schema) {
+	ownerName, err := refl.schemaName(s.owner)
+	if err != nil {
+		p.addError(err)
+	}
+	if ownerName == "" {
+		return
+	}
+	value := openapi3.NewArraySchema()
+	value.Items = &openapi3.SchemaRef{
+		Ref: p.refPath(refl, s),
+	}
+	value.Description = refl.comments.typeDoc(reflect.TypeOf(s.owner.object))
+	p.setSchema(refl, ownerName, value)
+	p.registerSchema(refl, NewSchema(s.object)) // need to register the child
+}
+
+// registered reports whether a component has already been registered by the
+// build, and marks it as being registered otherwise. Each component is
+// reflected once per build and a type referencing itself is found here while
+// it is being registered.
+func (p *Path) registered(refl *reflector, name string) bool {
+	if ref, ok := refl.schemas[name]; ok {
+		if ref != nil {
+			p.apiSchemas[name] = ref
+		}
+		return true
+	}
+	if refl.schemas == nil {
+		refl.schemas = make(map[string]*openapi3.SchemaRef)
+	}
+	refl.schemas[name] = nil
+	return false
+}
+
+// setSchema adds a component schema to the path and to the schemas already
+// registered by the build.
+func (p *Path) setSchema(refl *reflector, name string, value *openapi3.Schema) {
+	ref := openapi3.NewSchemaRef("", value)
+	p.apiSchemas[name] = ref
+	refl.schemas[name] = ref
 }
 
 func oapiSchemaFromProperty(property *Property) *openapi3.SchemaRef {
@@ -257,9 +492,19 @@ func oapiSchemaFromProperty(property *Property) *openapi3.SchemaRef {
 	}
 
 	if property.ref != "" {
-		return &openapi3.SchemaRef{
+		ref := &openapi3.SchemaRef{
 			Ref: property.ref,
 		}
+		// siblings of a $ref are ignored, a nullable reference is wrapped
+		if property.nullable {
+			return openapi3.NewSchemaRef("", &openapi3.Schema{Nullable: true, AllOf: openapi3.SchemaRefs{ref}})
+		}
+		return ref
+	}
+
+	enums := property.enums
+	if property._const != nil {
+		enums = []any{property._const}
 	}
 
 	var pType *openapi3.Types
@@ -270,133 +515,179 @@ func oapiSchemaFromProperty(property *Property) *openapi3.SchemaRef {
 		pType = &openapi3.Types{property._type}
 	}
 
-	return &openapi3.SchemaRef{
-		Value: &openapi3.Schema{
-			Type:        pType,
-			Format:      property.format,
-			Description: property.description,
-			Deprecated:  property.deprecated,
-			Default:     property._default,
-			Min:         property.minimum,
-			Max:         property.maximum,
-			Enum:        property.enums,
-			Nullable:    property.nullable,
-			Extensions:  property.extensions,
-			Items:       oapiSchemaFromProperty(property.itemsProp),
-			AdditionalProperties: openapi3.AdditionalProperties{
-				Schema: oapiSchemaFromProperty(property.additionalProperties),
-			},
+	value := &openapi3.Schema{
+		Type:         pType,
+		Format:       property.format,
+		Description:  property.description,
+		Deprecated:   property.deprecated,
+		Default:      property._default,
+		Min:          property.minimum,
+		Max:          property.maximum,
+		ExclusiveMin: property.exclusiveMinimum,
+		ExclusiveMax: property.exclusiveMaximum,
+		MultipleOf:   property.multipleOf,
+		MaxLength:    property.maxLength,
+		Pattern:      property.pattern,
+		MaxItems:     property.maxItems,
+		UniqueItems:  property.uniqueItems,
+		MaxProps:     property.maxProperties,
+		ReadOnly:     property.readOnly,
+		WriteOnly:    property.writeOnly,
+		Title:        property.title,
+		Example:      property.example,
+		Enum:         enums,
+		Nullable:     property.nullable,
+		Extensions:   property.extensions,
+		Items:        oapiSchemaFromProperty(property.itemsProp),
+		AdditionalProperties: openapi3.AdditionalProperties{
+			Schema: oapiSchemaFromProperty(property.additionalProperties),
 		},
 	}
+	if property.minLength != nil {
+		value.MinLength = *property.minLength
+	}
+	if property.minItems != nil {
+		value.MinItems = *property.minItems
+	}
+	if property.minProperties != nil {
+		value.MinProps = *property.minProperties
+	}
+	if property.properties != nil {
+		object := objectSchema(property.properties)
+		if len(object.AllOf) > 0 {
+			value.Type, value.AllOf = nil, object.AllOf
+		} else {
+			value.Properties, value.Required = object.Properties, object.Required
+		}
+	}
+	if property.schema != nil {
+		value = mergeSchemas(property.schema, value)
+	}
+	return openapi3.NewSchemaRef("", value)
 }
 
 func (p *Path) registerParameter(param *Parameter, oapiParam *openapi3.Parameter) {
-	value := openapi3.NewObjectSchema()
-
-	properties, _ := Properties(struct {
-		In     string
-		Name   string
-		Schema *openapi3.Schema
-	}{
-		In:     string(param.in),
-		Name:   string(param.name),
-		Schema: oapiParam.Schema.Value,
-	})
-
-	value.Properties = make(openapi3.Schemas)
-	for _, property := range properties {
-		if property.required {
-			value.Required = append(value.Required, property.name)
-		}
-		value.Properties[property.name] = oapiSchemaFromProperty(&property)
-	}
-	p.componentParameters[param.componentName] = &openapi3.ParameterRef{
+	p.components.parameters[param.componentName] = &openapi3.ParameterRef{
 		Value: oapiParam,
 	}
 }
 
-// after initial build
+// SetDefaultResponse overrides the default response of the path with the one
+// of the document, using the options of the last build. Build calls it, it
+// does nothing on a path which is not built yet.
 func (p *Path) SetDefaultResponse() {
-	if p.defaultResponse != nil {
-		p.apiResponses["default"] = &openapi3.ResponseRef{
-			Value: &openapi3.Response{
-				Description: &p.defaultResponse.description,
-			},
-		}
-		if p.defaultResponse.ref != nil {
-
-			p.apiResponses["default"].Value.Content = openapi3.Content{
-				"application/json": &openapi3.MediaType{
-					Schema: &openapi3.SchemaRef{
-						Ref: p.defaultResponse.ref.RefPath(),
-					},
-				},
-			}
-			p.registerSchema(p.defaultResponse.ref)
-		}
+	if p.refl != nil {
+		p.setDefaultResponse(p.refl)
 	}
 }
 
+// setDefaultResponse overrides the default response of the path with the one
+// of the document
+func (p *Path) setDefaultResponse(refl *reflector) {
+	if p.defaultResponse != nil {
+		p.apiResponses["default"] = p.response(refl, "default", p.defaultResponse)
+	}
+}
 func (p *Path) Response(r *Response) *Path {
-
 	p.responses = append(p.responses, r)
+	return p
+}
+
+func (p *Path) buildResponse(refl *reflector, r *Response) {
 	codeStr := fmt.Sprint(r.code)
 	if r.code == -1 {
 		codeStr = "default"
 	}
-	if r.inline != nil {
-		var openapiResp openapi3.Response
-		err := json.Unmarshal(r.inline, &openapiResp)
-		if err != nil {
-			panic(err)
-		}
-		p.apiResponses[codeStr] = &openapi3.ResponseRef{
-			Value: &openapiResp,
+	p.apiResponses[codeStr] = p.response(refl, codeStr, r)
+}
+
+// response builds a response, or a reference to it when it is a component.
+func (p *Path) response(refl *reflector, code string, r *Response) *openapi3.ResponseRef {
+	value := &openapi3.Response{
+		Description: &r.description,
+	}
+	content, err := p.content(refl, r.contents)
+	for _, err := range splitErrors(err) {
+		p.addError(fmt.Errorf("response %s: %w", code, err))
+	}
+	value.Content = content
+	if r.headers != nil {
+		value.Headers = make(openapi3.Headers)
+		for _, key := range slices.Sorted(maps.Keys(r.headers)) {
+			header, err := p.header(refl, r.headers[key])
+			if err != nil {
+				p.addError(fmt.Errorf("response %s: header %s: %w", code, key, err))
+				continue
+			}
+			value.Headers[key] = header
 		}
+	}
 
-	} else {
+	if r.componentName == "" {
+		return &openapi3.ResponseRef{Value: value}
+	}
+	p.components.responses[r.componentName] = &openapi3.ResponseRef{Value: value}
+	return &openapi3.ResponseRef{Ref: "#/components/responses/" + r.componentName}
+}
 
-		if r.ref == nil {
-			p.apiResponses[codeStr] = &openapi3.ResponseRef{
-				Value: &openapi3.Response{
-					Description: &r.description,
-				},
-			}
-		} else {
-			p.apiResponses[codeStr] = &openapi3.ResponseRef{
-				Value: &openapi3.Response{
-					Description: &r.description,
-					Content: openapi3.Content{
-						r.content: &openapi3.MediaType{
-							Schema: &openapi3.SchemaRef{
-								Ref: r.ref.RefPath(),
-							},
-						},
-					},
-				},
-			}
-			p.registerSchema(r.ref)
+// header builds a response header, or a reference to it when it is a
+// component.
+func (p *Path) header(refl *reflector, h *ResponseHeader) (*openapi3.HeaderRef, error) {
+	if h._type == nil {
+		return nil, errors.New("type is nil")
+	}
+	schema, err := p.typeSchemaRef(refl, h._type, false)
+	if err != nil {
+		return nil, err
+	}
+	value := &openapi3.Header{
+		Parameter: openapi3.Parameter{
+			Description: h.description,
+			Schema:      schema,
+		},
+	}
 
+	if h.componentName == "" {
+		return &openapi3.HeaderRef{Value: value}, nil
+	}
+	p.components.headers[h.componentName] = &openapi3.HeaderRef{Value: value}
+	return &openapi3.HeaderRef{Ref: "#/components/headers/" + h.componentName}, nil
+}
+
+// buildRequestBody builds the body of the request, or a reference to it when
+// it is a component.
+func (p *Path) buildRequestBody(refl *reflector, b *RequestBody) *openapi3.RequestBodyRef {
+	value := &openapi3.RequestBody{
+		Description: b.description,
+		Required:    b.required,
+	}
+	content, err := p.content(refl, b.contents)
+	for _, err := range splitErrors(err) {
+		p.addError(fmt.Errorf("request body: %w", err))
+	}
+	value.Content = content
+
+	if b.componentName == "" {
+		return &openapi3.RequestBodyRef{Value: value}
+	}
+	p.components.requestBodies[b.componentName] = &openapi3.RequestBodyRef{Value: value}
+	return &openapi3.RequestBodyRef{Ref: "#/components/requestBodies/" + b.componentName}
+}
+
+// examples builds the examples of a content, the components are referenced.
+func (p *Path) examples(examples map[string]*Example) openapi3.Examples {
+	if len(examples) == 0 {
+		return nil
+	}
+	ret := make(openapi3.Examples, len(examples))
+	for name, e := range examples {
+		value := e.value
+		if e.componentName == "" {
+			ret[name] = &openapi3.ExampleRef{Value: &value}
+			continue
 		}
-		if r.headers != nil {
-			p.apiResponses[codeStr].Value.Headers = make(openapi3.Headers)
-			for key, prop := range r.headers {
-
-				ptype := openapi3.Types([]string{prop._type})
-				p.apiResponses[codeStr].Value.Headers[key] = &openapi3.HeaderRef{
-					Value: &openapi3.Header{
-						Parameter: openapi3.Parameter{
-							Schema: &openapi3.SchemaRef{
-								Value: &openapi3.Schema{
-									Type:        &ptype,
-									Description: prop.description,
-								},
-							},
-						},
-					},
-				}
-			}
-		}
+		p.components.examples[e.componentName] = &openapi3.ExampleRef{Value: &value}
+		ret[name] = &openapi3.ExampleRef{Ref: "#/components/examples/" + e.componentName}
 	}
-	return p
+	return ret
 }
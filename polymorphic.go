@@ -0,0 +1,84 @@
+package openapigen
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+type composition string
+
+const (
+	oneOf composition = "oneOf"
+	anyOf composition = "anyOf"
+)
+
+// Polymorphic describes a value which can be one of several go types.
+// It can be used wherever a go type is expected (Response.JSON, Path.JSONBody...)
+// and it is registered as a component schema.
+type Polymorphic struct {
+	name          string
+	composition   composition
+	objects       []any
+	discriminator string
+	mapping       map[string]any
+}
+
+func OneOf(objects ...any) *Polymorphic {
+	return &Polymorphic{composition: oneOf, objects: objects}
+}
+
+func AnyOf(objects ...any) *Polymorphic {
+	return &Polymorphic{composition: anyOf, objects: objects}
+}
+
+// Name sets the name of the component, by default the names of the types are
+// joined with "Or".
+func (p *Polymorphic) Name(name string) *Polymorphic {
+	p.name = name
+	return p
+}
+
+// Discriminator sets the property used to distinguish the types. Values of
+// the mapping are either one of the types of p or a schema reference.
+func (p *Polymorphic) Discriminator(property string, mapping map[string]any) *Polymorphic {
+	p.discriminator = property
+	p.mapping = mapping
+	return p
+}
+
+func (p *Polymorphic) objectName() string {
+	if p.name != "" {
+		return p.name
+	}
+	names := make([]string, 0, len(p.objects))
+	for _, object := range p.objects {
+		names = append(names, NewSchema(object).ObjectName())
+	}
+	return strings.Join(names, "Or")
+}
+
+// RegisterInterface describes the struct fields of an interface type with a
+// polymorphic schema of its implementations. iface is a nil pointer to the
+// interface, like (*Animal)(nil).
+func (d *Document) RegisterInterface(iface any, implementations *Polymorphic) *Document {
+	_type := reflect.TypeOf(iface)
+	if _type == nil || _type.Kind() != reflect.Pointer || _type.Elem().Kind() != reflect.Interface {
+		d.errs = append(d.errs, typeError(_type, fmt.Errorf("%w: expected a pointer to an interface", ErrUnsupportedType)))
+		return d
+	}
+	_type = _type.Elem()
+	for _, object := range implementations.objects {
+		if object == nil || !reflect.TypeOf(object).Implements(_type) {
+			d.errs = append(d.errs, typeError(reflect.TypeOf(object), fmt.Errorf("does not implement %v", _type)))
+		}
+	}
+	if implementations.name == "" {
+		implementations.name = _type.Name()
+	}
+	if d.interfaces == nil {
+		d.interfaces = make(map[reflect.Type]*Polymorphic)
+	}
+	d.interfaces[_type] = implementations
+	return d
+}
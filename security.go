@@ -0,0 +1,219 @@
+package openapigen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+
+	"github.com/fmarmol/kin-openapi/openapi3"
+	"github.com/fmarmol/openapigen/utils"
+)
+
+// SecurityScheme describes a way to authenticate the requests, it is declared
+// on a document with a name used by the security requirements.
+type SecurityScheme struct {
+	value openapi3.SecurityScheme
+}
+
+// NewAPIKeyScheme describes an api key sent in a header, a query parameter or
+// a cookie.
+func NewAPIKeyScheme(in Pin, name string) *SecurityScheme {
+	return &SecurityScheme{value: openapi3.SecurityScheme{Type: "apiKey", In: string(in), Name: name}}
+}
+
+// NewHTTPScheme describes an http authorization scheme like "basic" or "bearer".
+func NewHTTPScheme(scheme string) *SecurityScheme {
+	return &SecurityScheme{value: openapi3.SecurityScheme{Type: "http", Scheme: scheme}}
+}
+
+func NewBasicAuthScheme() *SecurityScheme {
+	return NewHTTPScheme("basic")
+}
+
+// NewBearerScheme describes a bearer token, format is a hint like "JWT".
+func NewBearerScheme(format string) *SecurityScheme {
+	s := NewHTTPScheme("bearer")
+	s.value.BearerFormat = format
+	return s
+}
+
+// NewOAuth2Scheme describes an oauth2 authorization, its flows are added with
+// the AuthorizationCode, ClientCredentials, Implicit and Password methods.
+func NewOAuth2Scheme() *SecurityScheme {
+	return &SecurityScheme{value: openapi3.SecurityScheme{Type: "oauth2", Flows: &openapi3.OAuthFlows{}}}
+}
+
+func NewOpenIDConnectScheme(url string) *SecurityScheme {
+	return &SecurityScheme{value: openapi3.SecurityScheme{Type: "openIdConnect", OpenIdConnectUrl: url}}
+}
+
+func (s *SecurityScheme) Description(description string) *SecurityScheme {
+	s.value.Description = description
+	return s
+}
+
+func (s *SecurityScheme) flows() *openapi3.OAuthFlows {
+	if s.value.Flows == nil {
+		s.value.Flows = &openapi3.OAuthFlows{}
+	}
+	return s.value.Flows
+}
+
+// Scopes maps the name of a scope to its description.
+type Scopes = map[string]string
+
+func (s *SecurityScheme) AuthorizationCode(authorizationURL, tokenURL string, scopes Scopes) *SecurityScheme {
+	s.flows().AuthorizationCode = &openapi3.OAuthFlow{AuthorizationURL: authorizationURL, TokenURL: tokenURL, Scopes: scopes}
+	return s
+}
+
+func (s *SecurityScheme) ClientCredentials(tokenURL string, scopes Scopes) *SecurityScheme {
+	s.flows().ClientCredentials = &openapi3.OAuthFlow{TokenURL: tokenURL, Scopes: scopes}
+	return s
+}
+
+func (s *SecurityScheme) Implicit(authorizationURL string, scopes Scopes) *SecurityScheme {
+	s.flows().Implicit = &openapi3.OAuthFlow{AuthorizationURL: authorizationURL, Scopes: scopes}
+	return s
+}
+
+func (s *SecurityScheme) Password(tokenURL string, scopes Scopes) *SecurityScheme {
+	s.flows().Password = &openapi3.OAuthFlow{TokenURL: tokenURL, Scopes: scopes}
+	return s
+}
+
+// scopes returns all the scopes declared by the flows of an oauth2 scheme.
+func (s *SecurityScheme) scopes() []string {
+	var ret []string
+	if flows := s.value.Flows; flows != nil {
+		for _, flow := range []*openapi3.OAuthFlow{flows.AuthorizationCode, flows.ClientCredentials, flows.Implicit, flows.Password} {
+			if flow != nil {
+				for scope := range flow.Scopes {
+					ret = append(ret, scope)
+				}
+			}
+		}
+	}
+	return utils.Deduplicate(ret)
+}
+
+// SecurityScheme declares a security scheme on the document.
+func (d *Document) SecurityScheme(name string, s *SecurityScheme) *Document {
+	if d.securitySchemes == nil {
+		d.securitySchemes = make(map[string]*SecurityScheme)
+	}
+	d.securitySchemes[name] = s
+	return d
+}
+
+// Requirement is a set of security schemes, with their scopes, which must
+// all be satisfied to authorize a request.
+type Requirement struct {
+	value openapi3.SecurityRequirement
+}
+
+func Require(scheme string, scopes ...string) *Requirement {
+	return (&Requirement{value: openapi3.SecurityRequirement{}}).And(scheme, scopes...)
+}
+
+func (r *Requirement) And(scheme string, scopes ...string) *Requirement {
+	if scopes == nil {
+		scopes = []string{}
+	}
+	r.value[scheme] = scopes
+	return r
+}
+
+// Security adds a security requirement applied to all the paths of the
+// document, a request has to satisfy one of the requirements.
+func (d *Document) Security(scheme string, scopes ...string) *Document {
+	return d.SecurityRequirement(Require(scheme, scopes...))
+}
+
+// SecurityRequirement adds a requirement combining several schemes, like
+// Require("apiKey").And("oauth2", "read").
+func (d *Document) SecurityRequirement(r *Requirement) *Document {
+	d.security = addRequirement(d.security, r.value)
+	return d
+}
+
+// Security adds a security requirement to the operation, overriding the ones
+// of the document. A request has to satisfy one of the requirements.
+func (p *Path) Security(scheme string, scopes ...string) *Path {
+	return p.SecurityRequirement(Require(scheme, scopes...))
+}
+
+// SecurityRequirement adds a requirement combining several schemes, like
+// Require("apiKey").And("oauth2", "read").
+func (p *Path) SecurityRequirement(r *Requirement) *Path {
+	if p.security == nil {
+		p.security = openapi3.NewSecurityRequirements()
+	}
+	*p.security = addRequirement(*p.security, r.value)
+	return p
+}
+
+// addRequirement appends a requirement unless the same one, with the same
+// schemes and scopes, is already required.
+func addRequirement(requirements openapi3.SecurityRequirements, r openapi3.SecurityRequirement) openapi3.SecurityRequirements {
+	if slices.ContainsFunc(requirements, func(other openapi3.SecurityRequirement) bool { return reflect.DeepEqual(other, r) }) {
+		return requirements
+	}
+	return append(requirements, r)
+}
+
+// NoSecurity makes the operation public, whatever the requirements of the
+// document are.
+func (p *Path) NoSecurity() *Path {
+	p.security = openapi3.NewSecurityRequirements()
+	return p
+}
+
+// BearerAuth declares a bearer JWT scheme named bearerAuth and requires it on
+// all the paths of the document.
+func (d *Document) BearerAuth() *Document {
+	return d.SecurityScheme("bearerAuth", NewBearerScheme("JWT")).Security("bearerAuth")
+}
+
+func (d *Document) buildSecuritySchemes() (openapi3.SecuritySchemes, error) {
+	var errs []error
+	schemes := make(openapi3.SecuritySchemes, len(d.securitySchemes))
+	for _, name := range slices.Sorted(maps.Keys(d.securitySchemes)) {
+		scheme := d.securitySchemes[name]
+		if err := scheme.value.Validate(context.Background()); err != nil {
+			errs = append(errs, fmt.Errorf("security scheme %s: %w", name, err))
+		}
+		value := scheme.value
+		schemes[name] = &openapi3.SecuritySchemeRef{Value: &value}
+	}
+	return schemes, errors.Join(errs...)
+}
+
+// checkSecurity verifies the schemes and scopes used by security requirements
+// are declared on the document.
+func (d *Document) checkSecurity(requirements openapi3.SecurityRequirements) error {
+	var errs []error
+	for _, requirement := range requirements {
+		for _, name := range slices.Sorted(maps.Keys(requirement)) {
+			scopes := requirement[name]
+			scheme, ok := d.securitySchemes[name]
+			if !ok {
+				errs = append(errs, fmt.Errorf("security scheme %s is not declared", name))
+				continue
+			}
+			if scheme.value.Type != "oauth2" {
+				continue
+			}
+			declared := scheme.scopes()
+			for _, scope := range scopes {
+				if !slices.Contains(declared, scope) {
+					errs = append(errs, fmt.Errorf("security scheme %s: scope %s is not declared", name, scope))
+				}
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,89 @@
+package openapigen
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecuritySchemes(t *testing.T) {
+
+	doc := &Document{}
+	doc.
+		SecurityScheme("apiKey", NewAPIKeyScheme(HEADER, "X-Api-Key").Description("api key header")).
+		SecurityScheme("basic", NewBasicAuthScheme()).
+		SecurityScheme("oidc", NewOpenIDConnectScheme("https://example.com/.well-known/openid-configuration")).
+		SecurityScheme("oauth2", NewOAuth2Scheme().
+			AuthorizationCode("https://example.com/authorize", "https://example.com/token", Scopes{"read:movies": "read movies"}).
+			ClientCredentials("https://example.com/token", Scopes{"write:movies": "write movies"})).
+		Security("oauth2", "read:movies").
+		Security("apiKey").
+		Paths(NewPath("/movies").Get())
+
+	buffer := bytes.NewBuffer(nil)
+	err := doc.Write(buffer, 2)
+	fmt.Println(buffer.String())
+	require.NoError(t, err)
+	assert.Equal(t, testSecuritySchemesExpectedSpecs, strings.ReplaceAll(buffer.String(), "\n", ""))
+}
+
+func TestSecuritySchemesErrors(t *testing.T) {
+
+	doc := &Document{}
+	doc.
+		SecurityScheme("apiKey", NewAPIKeyScheme(HEADER, "")).
+		SecurityScheme("oauth2", NewOAuth2Scheme().Password("https://example.com/token", Scopes{"read": ""})).
+		Security("oauth2", "write").
+		Security("jwt")
+
+	err := doc.Build()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "security scheme apiKey:")
+	assert.Contains(t, err.Error(), "security scheme oauth2: scope write is not declared")
+	assert.Contains(t, err.Error(), "security scheme jwt is not declared")
+}
+
+func TestSecurityPath(t *testing.T) {
+
+	doc := &Document{}
+	doc.
+		SecurityScheme("apiKey", NewAPIKeyScheme(HEADER, "X-API-Key")).
+		SecurityScheme("oauth2", NewOAuth2Scheme().ClientCredentials("https://example.com/token", Scopes{"read:movies": ""})).
+		BearerAuth().
+		Paths(
+			NewPath("/health").Get().NoSecurity(),
+			NewPath("/movies").Get().
+				Security("oauth2", "read:movies").
+				SecurityRequirement(Require("apiKey").And("bearerAuth")),
+			NewPath("/me").Get(),
+		)
+
+	buffer := bytes.NewBuffer(nil)
+	err := doc.Write(buffer, 2)
+	fmt.Println(buffer.String())
+	require.NoError(t, err)
+	assert.Equal(t, testSecurityPathExpectedSpecs, strings.ReplaceAll(buffer.String(), "\n", ""))
+
+	doc.Path(NewPath("/admin").Post().Security("oauth2", "write:movies").Security("admin"))
+	err = doc.Build()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "POST /admin: security scheme oauth2: scope write:movies is not declared")
+	assert.Contains(t, err.Error(), "POST /admin: security scheme admin is not declared")
+}
+
+func TestSecurityDuplicates(t *testing.T) {
+
+	doc := &Document{}
+	doc.
+		BearerAuth().
+		BearerAuth().
+		Paths(NewPath("/movies").Get().Security("bearerAuth").Security("bearerAuth"))
+
+	require.NoError(t, doc.Build())
+	assert.Len(t, doc.t.Security, 1)
+	assert.Len(t, *doc.t.Paths.Value("/movies").Get.Security, 1)
+}
@@ -7,8 +7,6 @@ openapi: 3.0.0
 info:
   title: ""
   version: ""
-security: null
-tags: null
 paths:
   /items:
     get:
@@ -20,6 +18,10 @@ paths:
             items:
               $ref: '#/components/schemas/OrderBy'
             type: array
+        - in: query
+          name: status
+          schema:
+            $ref: '#/components/schemas/MyEnum'
       responses:
         default:
           description: ""
@@ -33,6 +35,11 @@ components:
           $ref: '#/components/schemas/OrderBy'
         type: array
   schemas:
+    MyEnum:
+      enum:
+        - FOO
+        - BAR
+      type: string
     OrderBy:
       properties:
         field:
@@ -44,3 +51,1329 @@ components:
         - order
       type: object
 `, "\n", "")
+
+var testBuilderEmbeddedExpectedSpecs = strings.ReplaceAll(`
+openapi: 3.0.0
+info:
+  title: ""
+  version: ""
+paths:
+  /accounts:
+    get:
+      responses:
+        "200":
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Account'
+          description: ok
+        default:
+          description: ""
+components:
+  schemas:
+    Account:
+      allOf:
+        - $ref: '#/components/schemas/Audit'
+        - properties:
+            id:
+              type: string
+            label:
+              type: string
+            name:
+              type: string
+            owner:
+              $ref: '#/components/schemas/Identity'
+          type: object
+    Audit:
+      properties:
+        created_at:
+          format: date-time
+          type: string
+        updated_at:
+          format: date-time
+          type: string
+      type: object
+    Identity:
+      properties:
+        id:
+          type: integer
+        name:
+          type: string
+      type: object
+`, "\n", "")
+
+var testBuilderPolymorphicExpectedSpecs = strings.ReplaceAll(`
+openapi: 3.0.0
+info:
+  title: ""
+  version: ""
+paths:
+  /pets:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/CatOrDog'
+        required: false
+      responses:
+        "200":
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Zoo'
+          description: ok
+        default:
+          description: ""
+components:
+  schemas:
+    Animal:
+      anyOf:
+        - $ref: '#/components/schemas/Cat'
+        - $ref: '#/components/schemas/Dog'
+    Cat:
+      properties:
+        kind:
+          type: string
+        lives:
+          type: integer
+      type: object
+    CatOrDog:
+      discriminator:
+        mapping:
+          cat: '#/components/schemas/Cat'
+          dog: '#/components/schemas/Dog'
+        propertyName: kind
+      oneOf:
+        - $ref: '#/components/schemas/Cat'
+        - $ref: '#/components/schemas/Dog'
+    Dog:
+      properties:
+        good:
+          type: boolean
+        kind:
+          type: string
+      type: object
+    Zoo:
+      properties:
+        animals:
+          items:
+            $ref: '#/components/schemas/Animal'
+          type: array
+      type: object
+`, "\n", "")
+
+var testSecuritySchemesExpectedSpecs = strings.ReplaceAll(`
+openapi: 3.0.0
+info:
+  title: ""
+  version: ""
+security:
+  - oauth2:
+      - read:movies
+  - apiKey: []
+paths:
+  /movies:
+    get:
+      responses:
+        default:
+          description: ""
+components:
+  securitySchemes:
+    apiKey:
+      description: api key header
+      in: header
+      name: X-Api-Key
+      type: apiKey
+    basic:
+      scheme: basic
+      type: http
+    oauth2:
+      flows:
+        authorizationCode:
+          authorizationUrl: https://example.com/authorize
+          scopes:
+            read:movies: read movies
+          tokenUrl: https://example.com/token
+        clientCredentials:
+          scopes:
+            write:movies: write movies
+          tokenUrl: https://example.com/token
+      type: oauth2
+    oidc:
+      openIdConnectUrl: https://example.com/.well-known/openid-configuration
+      type: openIdConnect
+`, "\n", "")
+
+var testSecurityPathExpectedSpecs = strings.ReplaceAll(`
+openapi: 3.0.0
+info:
+  title: ""
+  version: ""
+security:
+  - bearerAuth: []
+paths:
+  /health:
+    get:
+      responses:
+        default:
+          description: ""
+      security: []
+  /me:
+    get:
+      responses:
+        default:
+          description: ""
+  /movies:
+    get:
+      responses:
+        default:
+          description: ""
+      security:
+        - oauth2:
+            - read:movies
+        - apiKey: []
+          bearerAuth: []
+components:
+  securitySchemes:
+    apiKey:
+      in: header
+      name: X-API-Key
+      type: apiKey
+    bearerAuth:
+      bearerFormat: JWT
+      scheme: bearer
+      type: http
+    oauth2:
+      flows:
+        clientCredentials:
+          scopes:
+            read:movies: ""
+          tokenUrl: https://example.com/token
+      type: oauth2
+`, "\n", "")
+
+var testOpenAPI31ExpectedSpecs = strings.ReplaceAll(`
+openapi: 3.1.0
+info:
+  title: ratings
+  version: "1.0"
+jsonSchemaDialect: https://spec.openapis.org/oas/3.1/dialect/base
+paths:
+  /ratings:
+    get:
+      responses:
+        "200":
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Ratings'
+          description: ok
+        default:
+          description: ""
+webhooks:
+  newRating:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Rating'
+        required: false
+      responses:
+        "200":
+          description: ok
+        default:
+          description: ""
+components:
+  schemas:
+    MyEnum:
+      enum:
+        - FOO
+        - BAR
+      type: string
+    Rating:
+      properties:
+        genre:
+          $ref: '#/components/schemas/MyEnum'
+        kind:
+          const: movie
+          type: string
+        review:
+          type:
+            - string
+            - "null"
+        score:
+          exclusiveMaximum: 5
+          format: double
+          minimum: 0
+          type:
+            - number
+            - "null"
+      type: object
+    Ratings:
+      items:
+        $ref: '#/components/schemas/Rating'
+      type: array
+`, "\n", "")
+
+var testEncodeJSONExpectedSpecs = `{
+  "openapi": "3.0.0",
+  "info": {
+    "title": "movies",
+    "version": "1.0"
+  },
+  "servers": [
+    {
+      "url": "/api"
+    }
+  ],
+  "paths": {
+    "/movies": {
+      "get": {
+        "parameters": [
+          {
+            "in": "query",
+            "name": "year",
+            "schema": {
+              "minimum": 1900,
+              "type": "integer"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/Movies"
+                }
+              }
+            },
+            "description": "ok"
+          },
+          "default": {
+            "description": ""
+          }
+        }
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "Movie": {
+        "properties": {
+          "director_id": {
+            "type": "string"
+          },
+          "released_at": {
+            "type": "string"
+          },
+          "score": {
+            "format": "double",
+            "type": "number"
+          },
+          "title": {
+            "type": "string"
+          },
+          "year": {
+            "type": "integer"
+          }
+        },
+        "type": "object"
+      },
+      "Movies": {
+        "items": {
+          "$ref": "#/components/schemas/Movie"
+        },
+        "type": "array"
+      }
+    }
+  }
+}
+`
+
+var testBuilderRecursiveExpectedSpecs = strings.ReplaceAll(`
+openapi: 3.0.0
+info:
+  title: ""
+  version: ""
+paths:
+  /comments:
+    get:
+      responses:
+        "200":
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Comments'
+          description: ok
+        default:
+          description: ""
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Comment'
+        required: false
+      responses:
+        "201":
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Reply'
+          description: created
+        default:
+          description: ""
+  /tree:
+    get:
+      responses:
+        "200":
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Node'
+          description: ok
+        default:
+          description: ""
+components:
+  schemas:
+    Comment:
+      properties:
+        replies:
+          items:
+            $ref: '#/components/schemas/Reply'
+          type: array
+        text:
+          type: string
+      type: object
+    Comments:
+      items:
+        $ref: '#/components/schemas/Comment'
+      type: array
+    Node:
+      properties:
+        children:
+          items:
+            $ref: '#/components/schemas/Node'
+          type: array
+        name:
+          type: string
+        parent:
+          $ref: '#/components/schemas/Node'
+      type: object
+    Reply:
+      properties:
+        comments:
+          items:
+            $ref: '#/components/schemas/Comment'
+          type: array
+        text:
+          type: string
+      type: object
+`, "\n", "")
+
+var testBuilderInlineExpectedSpecs = strings.ReplaceAll(`
+openapi: 3.0.0
+info:
+  title: ""
+  version: ""
+paths:
+  /reports:
+    get:
+      responses:
+        "200":
+          content:
+            application/json:
+              schema:
+                items:
+                  type: string
+                type: array
+          description: names
+        "206":
+          content:
+            application/json:
+              schema:
+                items:
+                  items:
+                    $ref: '#/components/schemas/Movie'
+                  type: array
+                type: array
+          description: pages
+        "300":
+          content:
+            application/json:
+              schema:
+                items:
+                  $ref: '#/components/schemas/Movie'
+                maxItems: 3
+                minItems: 3
+                type: array
+          description: podium
+        default:
+          description: ""
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              properties:
+                history:
+                  items:
+                    properties:
+                      day:
+                        format: date-time
+                        type: string
+                      stats:
+                        $ref: '#/components/schemas/Stats'
+                    type: object
+                  type: array
+                name:
+                  type: string
+                stats:
+                  properties:
+                    count:
+                      type: integer
+                  type: object
+              type: object
+        required: true
+      responses:
+        "200":
+          content:
+            application/json:
+              schema:
+                properties:
+                  total:
+                    type: integer
+                type: object
+          description: ok
+        "201":
+          content:
+            application/json:
+              schema:
+                properties:
+                  count:
+                    type: integer
+                type: object
+          description: created
+        default:
+          description: ""
+components:
+  schemas:
+    Movie:
+      properties:
+        director_id:
+          type: string
+        released_at:
+          type: string
+        score:
+          format: double
+          type: number
+        title:
+          type: string
+        year:
+          type: integer
+      type: object
+    Stats:
+      properties:
+        count:
+          type: integer
+      type: object
+`, "\n", "")
+
+var testKeywordsExpectedSpecs = strings.ReplaceAll(`
+openapi: 3.0.0
+info:
+  title: ""
+  version: ""
+paths:
+  /signup:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Signup'
+        required: false
+      responses:
+        "204":
+          description: ok
+        default:
+          description: ""
+components:
+  schemas:
+    Signup:
+      properties:
+        age:
+          exclusiveMaximum: true
+          maximum: 130
+          minimum: 18
+          type: integer
+        emails:
+          items:
+            type: string
+          maxItems: 3
+          minItems: 1
+          type: array
+          uniqueItems: true
+        id:
+          example: 42
+          readOnly: true
+          type: integer
+        labels:
+          additionalProperties:
+            type: string
+          maxProperties: 10
+          minProperties: 1
+        login:
+          example: gopher
+          maxLength: 20
+          minLength: 3
+          pattern: ^[a-z]+$
+          title: Login
+          type: string
+        password:
+          minLength: 12
+          type: string
+          writeOnly: true
+        weight:
+          format: double
+          multipleOf: 0.5
+          type: number
+      type: object
+`, "\n", "")
+
+var testValidationTagsExpectedSpecs = strings.ReplaceAll(`
+openapi: 3.0.0
+info:
+  title: ""
+  version: ""
+paths:
+  /users:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/CreateUser'
+        required: false
+      responses:
+        "204":
+          description: ok
+        default:
+          description: ""
+components:
+  schemas:
+    CreateUser:
+      properties:
+        age:
+          maximum: 120
+          minimum: 0
+          type: integer
+        birthday:
+          format: date
+          type: string
+        email:
+          format: email
+          type: string
+        level:
+          enum:
+            - 1
+            - 2
+            - 3
+          type: integer
+        meta:
+          additionalProperties:
+            type: string
+          minProperties: 1
+        name:
+          maxLength: 64
+          minLength: 3
+          type: string
+        role:
+          enum:
+            - admin
+            - user
+            - guest
+          type: string
+        tags:
+          items:
+            minLength: 2
+            type: string
+          maxItems: 5
+          type: array
+        team:
+          format: uuid
+          type: string
+        website:
+          format: uri
+          type: string
+      required:
+        - name
+        - email
+      type: object
+`, "\n", "")
+
+var testRegisterTypeExpectedSpecs = strings.ReplaceAll(`
+openapi: 3.0.0
+info:
+  title: ""
+  version: ""
+paths:
+  /invoices:
+    get:
+      parameters:
+        - in: query
+          name: ids
+          schema:
+            items:
+              format: uuid
+              type: string
+            type: array
+      responses:
+        "200":
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Invoice'
+          description: ok
+          headers:
+            Last-Modified:
+              description: last invoice
+              schema:
+                format: date-time
+                type: string
+        default:
+          description: ""
+  /now:
+    get:
+      responses:
+        "200":
+          content:
+            application/json:
+              schema:
+                format: date-time
+                type: string
+          description: ok
+        default:
+          description: ""
+components:
+  schemas:
+    Invoice:
+      properties:
+        checksum:
+          items:
+            type: integer
+          maxItems: 4
+          minItems: 4
+          type: array
+        delay:
+          format: int64
+          type: integer
+        id:
+          format: uuid
+          type: string
+        issued:
+          format: date-time
+          type: string
+        lines:
+          items:
+            format: decimal
+            pattern: ^-?\d+(\.\d+)?$
+            type: string
+          type: array
+        link:
+          format: uri
+          type: string
+        note:
+          nullable: true
+          type: string
+        paid:
+          format: date-time
+          type: string
+        pdf:
+          format: byte
+          type: string
+        raw: {}
+        server:
+          type: string
+        total:
+          description: total amount
+          format: decimal
+          pattern: ^-?\d+(\.\d+)?$
+          type: string
+      type: object
+`, "\n", "")
+
+var testSchemaProviderExpectedSpecs = strings.ReplaceAll(`
+openapi: 3.0.0
+info:
+  title: ""
+  version: ""
+paths:
+  /products:
+    get:
+      parameters:
+        - in: query
+          name: max_price
+          schema:
+            example: 12.50 EUR
+            pattern: ^\d+\.\d{2} [A-Z]{3}$
+            type: string
+      responses:
+        "200":
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Product'
+          description: ok
+        default:
+          description: ""
+components:
+  schemas:
+    Product:
+      properties:
+        colors:
+          items:
+            format: hex-color
+            type: string
+          type: array
+        price:
+          example: 12.50 EUR
+          pattern: ^\d+\.\d{2} [A-Z]{3}$
+          type: string
+        promo:
+          description: promotional price
+          example: 12.50 EUR
+          pattern: ^\d+\.\d{2} [A-Z]{3}$
+          type: string
+      type: object
+`, "\n", "")
+
+var testEnumsExpectedSpecs = strings.ReplaceAll(`
+openapi: 3.0.0
+info:
+  title: ""
+  version: ""
+paths:
+  /tasks:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Task'
+        required: false
+      responses:
+        "204":
+          description: ok
+        default:
+          description: ""
+components:
+  schemas:
+    HTTPCode:
+      enum:
+        - 200
+        - 404
+      type: integer
+    Priority:
+      enum:
+        - 0
+        - 1
+      type: integer
+      x-enum-descriptions:
+        - when possible
+        - right now
+      x-enum-varnames:
+        - Low
+        - High
+    Ratio:
+      enum:
+        - 0.5
+        - 1
+        - 2
+      type: number
+    Task:
+      properties:
+        code:
+          $ref: '#/components/schemas/HTTPCode'
+        priority:
+          $ref: '#/components/schemas/Priority'
+        ratio:
+          $ref: '#/components/schemas/Ratio'
+      type: object
+`, "\n", "")
+
+var testDocCommentsExpectedSpecs = strings.ReplaceAll(`
+openapi: 3.0.0
+info:
+  title: ""
+  version: ""
+paths:
+  /books:
+    get:
+      responses:
+        "200":
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Books'
+          description: ok
+        default:
+          description: ""
+components:
+  schemas:
+    Book:
+      description: Book is a book of the library, identified by its ISBN.
+      properties:
+        author:
+          description: full name
+          type: string
+        genre:
+          $ref: '#/components/schemas/Genre'
+        isbn:
+          description: ISBN is the international standard book number, with or without dashes.
+          type: string
+        pages:
+          type: integer
+        title:
+          description: title in the original language
+          type: string
+      type: object
+    Books:
+      description: Books are the books of a shelf.
+      items:
+        $ref: '#/components/schemas/Book'
+      type: array
+    Genre:
+      description: Genre of a book.
+      enum:
+        - fiction
+        - poetry
+      type: string
+`, "\n", "")
+
+var testTagsExpectedSpecs = strings.ReplaceAll(`
+openapi: 3.0.0
+info:
+  title: ""
+  version: ""
+paths:
+  /articles:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Article'
+        required: false
+      responses:
+        "204":
+          description: ok
+        default:
+          description: ""
+components:
+  schemas:
+    Article:
+      properties:
+        draft:
+          deprecated: true
+          type: boolean
+        format:
+          default: markdown
+          description: markdown or html
+          type: string
+        summary:
+          description: first paragraph, shortened
+          type: string
+        title:
+          description: title, in plain text
+          minLength: 1
+          type: string
+      required:
+        - summary
+      type: object
+`, "\n", "")
+
+var testParametersFromExpectedSpecs = strings.ReplaceAll(`
+openapi: 3.0.0
+info:
+  title: ""
+  version: ""
+paths:
+  /studios/{studio_id}/movies:
+    get:
+      parameters:
+        - in: query
+          name: page
+          schema:
+            default: 1
+            minimum: 1
+            type: integer
+        - in: query
+          name: per_page
+          schema:
+            maximum: 100
+            type: integer
+        - in: path
+          name: studio_id
+          required: true
+          schema:
+            format: uuid
+            type: string
+        - description: genre of the movies
+          in: query
+          name: genre
+          schema:
+            $ref: '#/components/schemas/Priority'
+        - in: query
+          name: sort
+          schema:
+            items:
+              type: string
+            type: array
+            uniqueItems: true
+        - deprecated: true
+          in: query
+          name: before
+          schema:
+            format: date
+            type: string
+        - in: header
+          name: X-Request-ID
+          required: true
+          schema:
+            format: uuid
+            type: string
+        - in: cookie
+          name: session
+          schema:
+            type: string
+      responses:
+        "204":
+          description: ok
+        default:
+          description: ""
+components:
+  schemas:
+    Priority:
+      enum:
+        - 0
+        - 1
+      type: integer
+      x-enum-descriptions:
+        - when possible
+        - right now
+      x-enum-varnames:
+        - Low
+        - High
+`, "\n", "")
+
+var testParameterOptionsExpectedSpecs = strings.ReplaceAll(`
+openapi: 3.0.0
+info:
+  title: ""
+  version: ""
+paths:
+  /movies:
+    get:
+      parameters:
+        - explode: true
+          in: query
+          name: filter
+          schema:
+            $ref: '#/components/schemas/MovieFilter'
+          style: deepObject
+        - description: comma separated identifiers
+          explode: false
+          in: query
+          name: ids
+          schema:
+            items:
+              type: integer
+            type: array
+        - in: query
+          name: tags
+          schema:
+            items:
+              type: string
+            type: array
+          style: pipeDelimited
+        - allowReserved: true
+          example: /movies?page=2
+          in: query
+          name: redirect
+          schema:
+            type: string
+        - allowEmptyValue: true
+          in: query
+          name: q
+          schema:
+            type: string
+        - in: cookie
+          name: session
+          required: true
+          schema:
+            type: string
+        - deprecated: true
+          in: header
+          name: X-Api-Version
+          schema:
+            type: integer
+      responses:
+        "204":
+          description: ok
+        default:
+          description: ""
+components:
+  schemas:
+    MovieFilter:
+      properties:
+        status:
+          type: string
+        year:
+          type: integer
+      type: object
+`, "\n", "")
+
+var testPathTemplatesExpectedSpecs = strings.ReplaceAll(`
+openapi: 3.0.0
+info:
+  title: ""
+  version: ""
+paths:
+  /static/{path}:
+    get:
+      parameters:
+        - in: path
+          name: path
+          required: true
+          schema:
+            type: string
+      responses:
+        "204":
+          description: ok
+        default:
+          description: ""
+  /users/:
+    get:
+      responses:
+        "204":
+          description: ok
+        default:
+          description: ""
+  /users/{user_id}/posts/{id}:
+    get:
+      parameters:
+        - in: path
+          name: id
+          required: true
+          schema:
+            type: integer
+        - in: path
+          name: user_id
+          required: true
+          schema:
+            type: string
+      responses:
+        "204":
+          description: ok
+        default:
+          description: ""
+components: {}
+`, "\n", "")
+
+var testComponentsExpectedSpecs = strings.ReplaceAll(`
+openapi: 3.0.0
+info:
+  title: ""
+  version: ""
+paths:
+  /movies:
+    post:
+      requestBody:
+        $ref: '#/components/requestBodies/MovieBody'
+      responses:
+        "201":
+          content:
+            application/json:
+              examples:
+                alien:
+                  $ref: '#/components/examples/Alien'
+              schema:
+                $ref: '#/components/schemas/Movie'
+          description: created
+          headers:
+            X-RateLimit-Remaining:
+              $ref: '#/components/headers/RateLimitRemaining'
+        default:
+          description: ""
+  /movies/{id}:
+    put:
+      parameters:
+        - in: path
+          name: id
+          required: true
+          schema:
+            type: string
+      requestBody:
+        $ref: '#/components/requestBodies/MovieBody'
+      responses:
+        "200":
+          content:
+            application/json:
+              examples:
+                inline:
+                  value:
+                    title: Heat
+              schema:
+                $ref: '#/components/schemas/Movie'
+          description: updated
+          headers:
+            X-RateLimit-Remaining:
+              $ref: '#/components/headers/RateLimitRemaining'
+        "404":
+          $ref: '#/components/responses/NotFound'
+        default:
+          description: ""
+    delete:
+      parameters:
+        - in: path
+          name: id
+          required: true
+          schema:
+            type: string
+      responses:
+        "204":
+          description: deleted
+        "404":
+          $ref: '#/components/responses/NotFound'
+        default:
+          description: ""
+components:
+  examples:
+    Alien:
+      summary: a classic
+      value:
+        title: Alien
+        year: 1979
+  headers:
+    RateLimitRemaining:
+      description: requests left in the window
+      schema:
+        type: integer
+  requestBodies:
+    MovieBody:
+      content:
+        application/json:
+          examples:
+            alien:
+              $ref: '#/components/examples/Alien'
+          schema:
+            $ref: '#/components/schemas/Movie'
+      description: movie to save
+      required: true
+  responses:
+    NotFound:
+      content:
+        application/json:
+          schema:
+            $ref: '#/components/schemas/ProblemDetails'
+      description: not found
+  schemas:
+    Movie:
+      properties:
+        director_id:
+          type: string
+        released_at:
+          type: string
+        score:
+          format: double
+          type: number
+        title:
+          type: string
+        year:
+          type: integer
+      type: object
+    ProblemDetails:
+      properties:
+        status:
+          type: integer
+        title:
+          type: string
+      type: object
+`, "\n", "")
+
+var testContentsExpectedSpecs = strings.ReplaceAll(`
+openapi: 3.0.0
+info:
+  title: ""
+  version: ""
+paths:
+  /kid:
+    get:
+      responses:
+        "200":
+          content:
+            application/json:
+              examples:
+                ada:
+                  value:
+                    my_name: Ada
+              schema:
+                $ref: '#/components/schemas/Kid'
+            text/csv:
+              examples:
+                csv:
+                  value: |-
+                    my_name
+                    Ada
+              schema:
+                type: string
+          description: kid
+        default:
+          description: ""
+  /uploads:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Upload'
+          multipart/form-data:
+            encoding:
+              file:
+                contentType: image/png, image/jpeg
+              labels:
+                contentType: text/plain
+                explode: true
+                style: form
+            schema:
+              $ref: '#/components/schemas/Upload'
+        required: true
+      responses:
+        "204":
+          description: ok
+        default:
+          description: ""
+components:
+  schemas:
+    Kid:
+      properties:
+        my_name:
+          type: string
+      type: object
+    Upload:
+      properties:
+        file:
+          format: binary
+          type: string
+        labels:
+          items:
+            type: string
+          type: array
+        name:
+          type: string
+      type: object
+`, "\n", "")
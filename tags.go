@@ -0,0 +1,193 @@
+package openapigen
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// tagKeys lists the keys of the oapi tag, the boolean ones can be written
+// alone as a shorthand for key:true.
+var tagKeys = map[string]bool{
+	"name":             false,
+	"format":           false,
+	"description":      false,
+	"deprecated":       true,
+	"default":          false,
+	"title":            false,
+	"const":            false,
+	"example":          false,
+	"required":         true,
+	"nullable":         true,
+	"inline":           true,
+	"allOf":            true,
+	"readOnly":         true,
+	"writeOnly":        true,
+	"min":              false,
+	"max":              false,
+	"exclusiveMinimum": true,
+	"exclusiveMaximum": true,
+	"multipleOf":       false,
+	"minLength":        false,
+	"maxLength":        false,
+	"pattern":          false,
+	"minItems":         false,
+	"maxItems":         false,
+	"uniqueItems":      true,
+	"minProperties":    false,
+	"maxProperties":    false,
+}
+
+// tagEntry is a key of an oapi tag and its unquoted value, raw is the entry
+// as written in the tag.
+type tagEntry struct {
+	key   string
+	value string
+	raw   string
+}
+
+// oapiTag is a parsed oapi tag, its entries are kept in the order of the tag.
+type oapiTag []tagEntry
+
+// parseTag parses an oapi tag: comma separated entries written key:value, or
+// key alone for a boolean. A value wrapped in single quotes can contain commas
+// and a backslash escapes a comma, a quote or a backslash.
+func parseTag(tag string) (oapiTag, error) {
+	if strings.TrimSpace(tag) == "" {
+		return nil, nil
+	}
+	raws, err := splitTag(tag)
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	var ret oapiTag
+	for _, raw := range raws {
+		entry, err := parseTagEntry(raw)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if ret.has(entry.key) {
+			errs = append(errs, fmt.Errorf("duplicate key %q", entry.key))
+			continue
+		}
+		ret = append(ret, entry)
+	}
+	return ret, errors.Join(errs...)
+}
+
+// splitTag splits a tag on the commas which are neither quoted nor escaped.
+func splitTag(tag string) ([]string, error) {
+	var ret []string
+	quoted, escaped := false, false
+	start := 0
+	for i := 0; i < len(tag); i++ {
+		switch c := tag[i]; {
+		case escaped:
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case c == '\'' && (quoted || isValueStart(tag[start:i])):
+			quoted = !quoted
+		case c == ',' && !quoted:
+			ret = append(ret, strings.TrimSpace(tag[start:i]))
+			start = i + 1
+		}
+	}
+	if quoted {
+		return nil, fmt.Errorf("unterminated quote in %q", tag[start:])
+	}
+	return append(ret, strings.TrimSpace(tag[start:])), nil
+}
+
+// isValueStart reports whether a quote following the beginning of an entry
+// opens its value, quotes in the middle of a value are kept as is.
+func isValueStart(entry string) bool {
+	_, value, ok := strings.Cut(entry, ":")
+	return ok && strings.TrimSpace(value) == ""
+}
+
+func parseTagEntry(raw string) (tagEntry, error) {
+	if raw == "" {
+		return tagEntry{}, errors.New("empty entry")
+	}
+	key, value, hasValue := strings.Cut(raw, ":")
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return tagEntry{}, fmt.Errorf("missing key in %q", raw)
+	}
+	boolean, known := tagKeys[key]
+	if !known {
+		return tagEntry{}, fmt.Errorf("unknown key %q", key)
+	}
+	entry := tagEntry{key: key, raw: raw}
+	if !hasValue {
+		if !boolean {
+			return tagEntry{}, fmt.Errorf("%s: missing value", key)
+		}
+		entry.value = "true"
+		return entry, nil
+	}
+
+	value, err := unquoteTag(strings.TrimSpace(value))
+	if err != nil {
+		return tagEntry{}, fmt.Errorf("%s: %w", key, err)
+	}
+	entry.value = value
+	if boolean {
+		if _, err := strconv.ParseBool(entry.value); err != nil {
+			return tagEntry{}, fmt.Errorf("%s: %q is not a boolean", key, entry.value)
+		}
+	}
+	return entry, nil
+}
+
+// unquoteTag removes the quotes around a value and the backslashes escaping a
+// comma, a quote or a backslash, the other ones are kept, like in a pattern.
+func unquoteTag(value string) (string, error) {
+	quoted := strings.HasPrefix(value, "'")
+	if quoted {
+		value = value[1:]
+	}
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		switch {
+		case value[i] == '\\' && i+1 < len(value) && strings.IndexByte(`,'\`, value[i+1]) >= 0:
+			i++
+		case value[i] == '\'' && quoted:
+			if i != len(value)-1 {
+				return "", fmt.Errorf("unexpected %q after the quoted value", value[i+1:])
+			}
+			return b.String(), nil
+		}
+		b.WriteByte(value[i])
+	}
+	if quoted {
+		return "", errors.New("unterminated quote")
+	}
+	return b.String(), nil
+}
+
+func (t oapiTag) lookup(key string) (string, bool) {
+	for _, entry := range t {
+		if entry.key == key {
+			return entry.value, true
+		}
+	}
+	return "", false
+}
+
+func (t oapiTag) has(key string) bool {
+	_, ok := t.lookup(key)
+	return ok
+}
+
+// flag returns the value of a boolean key, false when it is not set.
+func (t oapiTag) flag(key string) bool {
+	value, _ := t.lookup(key)
+	ok, _ := strconv.ParseBool(value)
+	return ok
+}
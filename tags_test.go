@@ -0,0 +1,94 @@
+package openapigen
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTag(t *testing.T) {
+	tests := []struct {
+		tag    string
+		values map[string]string
+		err    string
+	}{
+		{tag: "", values: map[string]string{}},
+		{tag: "name:id, required", values: map[string]string{"name": "id", "required": "true"}},
+		{tag: "required:false,nullable:true", values: map[string]string{"required": "false", "nullable": "true"}},
+		{tag: "description:'a, b',format:email", values: map[string]string{"description": "a, b", "format": "email"}},
+		{tag: `description:a\, b`, values: map[string]string{"description": "a, b"}},
+		{tag: `description:don't`, values: map[string]string{"description": "don't"}},
+		{tag: `description:'it\'s'`, values: map[string]string{"description": "it's"}},
+		{tag: `pattern:'^\d{1,3}$'`, values: map[string]string{"pattern": `^\d{1,3}$`}},
+		{tag: "description:format:email", values: map[string]string{"description": "format:email"}},
+		{tag: "nam:id", err: `unknown key "nam"`},
+		{tag: "name", err: "name: missing value"},
+		{tag: "required:yes", err: `required: "yes" is not a boolean`},
+		{tag: "name:a,name:b", err: `duplicate key "name"`},
+		{tag: "description:'a, b", err: `unterminated quote in "description:'a, b"`},
+		{tag: "description:'a'b", err: `description: unexpected "b" after the quoted value`},
+		{tag: ":id", err: `missing key in ":id"`},
+		{tag: "name:id,", err: "empty entry"},
+	}
+	for _, test := range tests {
+		t.Run(test.tag, func(t *testing.T) {
+			tag, err := parseTag(test.tag)
+			if test.err != "" {
+				assert.EqualError(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+			values := make(map[string]string)
+			for _, entry := range tag {
+				values[entry.key] = entry.value
+			}
+			assert.Equal(t, test.values, values)
+		})
+	}
+}
+
+type Article struct {
+	Title   string `json:"title" oapi:"description:'title, in plain text',minLength:1"`
+	Summary string `json:"summary,omitempty" oapi:"description:first paragraph\\, shortened,required"`
+	Format  string `json:"format" oapi:"description:markdown or html,default:markdown"`
+	Draft   bool   `json:"draft" oapi:"deprecated,required:false"`
+}
+
+func TestTags(t *testing.T) {
+
+	doc := &Document{}
+	doc.
+		Paths(
+			NewPath("/articles").Post().
+				JSONBody(Article{}).
+				Responses(NewResponse(204).Description("ok")),
+		)
+
+	buffer := bytes.NewBuffer(nil)
+	err := doc.Write(buffer, 2)
+	fmt.Println(buffer.String())
+	require.NoError(t, err)
+	assert.Equal(t, testTagsExpectedSpecs, strings.ReplaceAll(buffer.String(), "\n", ""))
+}
+
+type InvalidTags struct {
+	Name  string `json:"name" oapi:"nmae:id"`
+	Count int    `json:"count" oapi:"required:maybe,min:1"`
+	Text  string `json:"text" oapi:"description:'unterminated"`
+}
+
+func TestTagsErrors(t *testing.T) {
+
+	_, _, err := Properties(InvalidTags{})
+	require.Error(t, err)
+
+	errs := splitErrors(err)
+	require.Len(t, errs, 3)
+	assert.EqualError(t, errs[0], `type openapigen.InvalidTags, field Name: oapi tag: unknown key "nmae"`)
+	assert.EqualError(t, errs[1], `type openapigen.InvalidTags, field Count: oapi tag: required: "maybe" is not a boolean`)
+	assert.EqualError(t, errs[2], `type openapigen.InvalidTags, field Text: oapi tag: unterminated quote in "description:'unterminated"`)
+}
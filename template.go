@@ -0,0 +1,111 @@
+package openapigen
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/fmarmol/kin-openapi/openapi3"
+)
+
+// pathTemplate is a parsed path like /users/{id}. The wildcards of a go
+// ServeMux pattern, {path...} and {$}, are accepted and written with the
+// openapi syntax.
+type pathTemplate struct {
+	path   string   // path written in the document
+	params []string // names of the placeholders, in order
+}
+
+func parsePathTemplate(path string) (pathTemplate, error) {
+	var ret pathTemplate
+	var b strings.Builder
+	for rest := path; rest != ""; {
+		open := strings.IndexAny(rest, "{}")
+		if open < 0 {
+			b.WriteString(rest)
+			break
+		}
+		if rest[open] == '}' {
+			return ret, fmt.Errorf("path %s: unexpected }", path)
+		}
+		b.WriteString(rest[:open])
+		end := strings.IndexAny(rest[open+1:], "{}")
+		if end < 0 || rest[open+1+end] == '{' {
+			return ret, fmt.Errorf("path %s: unterminated placeholder", path)
+		}
+		name := rest[open+1 : open+1+end]
+		rest = rest[open+end+2:]
+
+		segmentStart := strings.HasSuffix(b.String(), "/")
+		switch {
+		case name == "$":
+			// {$} only anchors the end of a ServeMux pattern
+			if !segmentStart || rest != "" {
+				return ret, fmt.Errorf("path %s: {$} has to end the path", path)
+			}
+			continue
+		case strings.HasSuffix(name, "..."):
+			name = strings.TrimSuffix(name, "...")
+			if !segmentStart || rest != "" {
+				return ret, fmt.Errorf("path %s: {%s...} has to be the last segment of the path", path, name)
+			}
+		}
+		if name == "" || strings.Contains(name, "/") {
+			return ret, fmt.Errorf("path %s: invalid placeholder {%s}", path, name)
+		}
+		if slices.Contains(ret.params, name) {
+			return ret, fmt.Errorf("path %s: placeholder {%s} is used twice", path, name)
+		}
+		ret.params = append(ret.params, name)
+		b.WriteString("{" + name + "}")
+	}
+	ret.path = b.String()
+	return ret, nil
+}
+
+// StrictPathParameters reports the placeholders of the path templates which
+// have no path parameter, instead of declaring them as required strings.
+func (d *Document) StrictPathParameters() *Document {
+	d.strictPathParameters = true
+	return d
+}
+
+// pathParameters matches the path parameters of an operation with the
+// placeholders of its path template. The undeclared placeholders are added to
+// the operation as required strings, unless the document is strict.
+func (d *Document) pathParameters(template pathTemplate, operation *openapi3.Operation) error {
+	var errs []error
+	var declared []string
+	for _, paramRef := range operation.Parameters {
+		param := paramRef.Value
+		if param == nil {
+			if ref := d.t.Components.Parameters[strings.TrimPrefix(paramRef.Ref, "#/components/parameters/")]; ref != nil {
+				param = ref.Value
+			}
+		}
+		if param == nil || param.In != string(PATH) {
+			continue
+		}
+		declared = append(declared, param.Name)
+		if !slices.Contains(template.params, param.Name) {
+			errs = append(errs, fmt.Errorf("path parameter %s is not in the path template", param.Name))
+		} else if !param.Required {
+			errs = append(errs, fmt.Errorf("path parameter %s has to be required", param.Name))
+		}
+	}
+
+	for _, name := range template.params {
+		if slices.Contains(declared, name) {
+			continue
+		}
+		if d.strictPathParameters {
+			errs = append(errs, fmt.Errorf("path parameter %s is not declared", name))
+			continue
+		}
+		operation.Parameters = append(operation.Parameters, &openapi3.ParameterRef{
+			Value: openapi3.NewPathParameter(name).WithSchema(openapi3.NewStringSchema()),
+		})
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,101 @@
+package openapigen
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePathTemplate(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+		params   []string
+		err      string
+	}{
+		{path: "/users", expected: "/users"},
+		{path: "/users/{id}/posts/{post_id}", expected: "/users/{id}/posts/{post_id}", params: []string{"id", "post_id"}},
+		{path: "/files/{name}.json", expected: "/files/{name}.json", params: []string{"name"}},
+		{path: "/static/{path...}", expected: "/static/{path}", params: []string{"path"}},
+		{path: "/users/{$}", expected: "/users/"},
+		{path: "/users/{id", err: "path /users/{id: unterminated placeholder"},
+		{path: "/users/{a{b}}", err: "path /users/{a{b}}: unterminated placeholder"},
+		{path: "/users/id}", err: "path /users/id}: unexpected }"},
+		{path: "/users/{}", err: "path /users/{}: invalid placeholder {}"},
+		{path: "/users/{id}/{id}", err: "path /users/{id}/{id}: placeholder {id} is used twice"},
+		{path: "/static/{path...}/raw", err: "path /static/{path...}/raw: {path...} has to be the last segment of the path"},
+		{path: "/static/v{path...}", err: "path /static/v{path...}: {path...} has to be the last segment of the path"},
+		{path: "/users/{$}/x", err: "path /users/{$}/x: {$} has to end the path"},
+	}
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			template, err := parsePathTemplate(test.path)
+			if test.err != "" {
+				assert.EqualError(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, template.path)
+			assert.Equal(t, test.params, template.params)
+		})
+	}
+}
+
+func TestPathTemplates(t *testing.T) {
+
+	doc := &Document{}
+	doc.
+		Paths(
+			NewPath("/users/{user_id}/posts/{id}").Get().
+				Parameter(NewParameter("id").InPath().Type("integer").Required()).
+				Responses(NewResponse(204).Description("ok")),
+			NewPath("/static/{path...}").Get().
+				Responses(NewResponse(204).Description("ok")),
+			NewPath("/users/{$}").Get().
+				Responses(NewResponse(204).Description("ok")),
+		)
+
+	buffer := bytes.NewBuffer(nil)
+	err := doc.Write(buffer, 2)
+	fmt.Println(buffer.String())
+	require.NoError(t, err)
+	assert.Equal(t, testPathTemplatesExpectedSpecs, strings.ReplaceAll(buffer.String(), "\n", ""))
+}
+
+var StudioPathParam = NewParameter("studio").
+	InPath().
+	Type("string").
+	AsComponent("studioPathParam")
+
+func TestPathTemplatesErrors(t *testing.T) {
+
+	doc := &Document{}
+	doc.
+		StrictPathParameters().
+		Paths(
+			NewPath("/movies/{id}").Get().
+				Parameter(NewParameter("id").InPath().Type("integer")).
+				Parameter(NewParameter("slug").InPath().Type("string").Required()).
+				Responses(NewResponse(204).Description("ok")),
+			NewPath("/studios/{id}").Get().
+				Parameter(StudioPathParam).
+				Responses(NewResponse(204).Description("ok")),
+			NewPath("/files/{path...}/raw").Get().
+				Responses(NewResponse(204).Description("ok")),
+		)
+
+	err := doc.Build()
+	require.Error(t, err)
+
+	errs := splitErrors(err)
+	require.Len(t, errs, 5)
+	assert.EqualError(t, errs[0], "GET /movies/{id}: path parameter id has to be required")
+	assert.EqualError(t, errs[1], "GET /movies/{id}: path parameter slug is not in the path template")
+	assert.EqualError(t, errs[2], "GET /studios/{id}: path parameter studio is not in the path template")
+	assert.EqualError(t, errs[3], "GET /studios/{id}: path parameter id is not declared")
+	assert.EqualError(t, errs[4], "GET /files/{path...}/raw: path /files/{path...}/raw: {path...} has to be the last segment of the path")
+}
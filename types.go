@@ -0,0 +1,145 @@
+package openapigen
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"maps"
+	"math/big"
+	"net"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"time"
+
+	"github.com/fmarmol/kin-openapi/openapi3"
+	"github.com/google/uuid"
+)
+
+// SchemaProvider is implemented by types describing their own schema, like a
+// type with its own json encoding. The method is called on the zero value.
+type SchemaProvider interface {
+	OpenAPISchema() *openapi3.Schema
+}
+
+// SchemaFunc returns the schema describing a go type, a new schema has to be
+// returned on each call.
+type SchemaFunc func(_type reflect.Type) *openapi3.Schema
+
+// RegisterType describes a go type with a schema instead of reflecting it,
+// like a type with its own json encoding. It overrides the default
+// descriptions of the standard library types.
+func (d *Document) RegisterType(_type reflect.Type, f SchemaFunc) *Document {
+	if _type == nil || f == nil {
+		d.errs = append(d.errs, errors.New("register type: type and schema function are required"))
+		return d
+	}
+	if d.types == nil {
+		d.types = make(map[reflect.Type]SchemaFunc)
+	}
+	d.types[_type] = f
+	return d
+}
+
+func scalarSchema(_type, format string, nullable bool) SchemaFunc {
+	return func(reflect.Type) *openapi3.Schema {
+		return &openapi3.Schema{Type: &openapi3.Types{_type}, Format: format, Nullable: nullable}
+	}
+}
+
+// defaultTypes describes the types whose json encoding is not the one of their
+// kind.
+var defaultTypes = map[reflect.Type]SchemaFunc{
+	reflect.TypeFor[time.Time]():       scalarSchema("string", "date-time", false),
+	reflect.TypeFor[time.Duration]():   scalarSchema("integer", "int64", false),
+	reflect.TypeFor[uuid.UUID]():       scalarSchema("string", "uuid", false),
+	reflect.TypeFor[url.URL]():         scalarSchema("string", "uri", false),
+	reflect.TypeFor[net.IP]():          scalarSchema("string", "", false),
+	reflect.TypeFor[netip.Addr]():      scalarSchema("string", "", false),
+	reflect.TypeFor[netip.AddrPort]():  scalarSchema("string", "", false),
+	reflect.TypeFor[netip.Prefix]():    scalarSchema("string", "", false),
+	reflect.TypeFor[[]byte]():          scalarSchema("string", "byte", false),
+	reflect.TypeFor[json.RawMessage](): func(reflect.Type) *openapi3.Schema { return &openapi3.Schema{} },
+	reflect.TypeFor[big.Int]():         scalarSchema("integer", "", false),
+	reflect.TypeFor[big.Float]():       scalarSchema("string", "", false),
+	reflect.TypeFor[big.Rat]():         scalarSchema("string", "", false),
+	reflect.TypeFor[sql.NullString]():  scalarSchema("string", "", true),
+	reflect.TypeFor[sql.NullBool]():    scalarSchema("boolean", "", true),
+	reflect.TypeFor[sql.NullByte]():    scalarSchema("integer", "", true),
+	reflect.TypeFor[sql.NullInt16]():   scalarSchema("integer", "", true),
+	reflect.TypeFor[sql.NullInt32]():   scalarSchema("integer", "int32", true),
+	reflect.TypeFor[sql.NullInt64]():   scalarSchema("integer", "int64", true),
+	reflect.TypeFor[sql.NullFloat64](): scalarSchema("number", "double", true),
+	reflect.TypeFor[sql.NullTime]():    scalarSchema("string", "date-time", true),
+}
+
+// schemaFunc returns how a type is described when it is not reflected: with
+// the function registered on the document, the schema provided by the type or
+// a default for the standard library types.
+func (r *reflector) schemaFunc(_type reflect.Type) SchemaFunc {
+	if f, ok := r.types[_type]; ok {
+		return f
+	}
+	if _type.Kind() == reflect.Pointer {
+		return nil
+	}
+	if _type.Implements(_schemaProviderImpl) || reflect.PointerTo(_type).Implements(_schemaProviderImpl) {
+		return providedSchema
+	}
+	return defaultTypes[_type]
+}
+
+func providedSchema(_type reflect.Type) *openapi3.Schema {
+	provider, ok := reflect.New(_type).Elem().Interface().(SchemaProvider)
+	if !ok {
+		provider = reflect.New(_type).Interface().(SchemaProvider)
+	}
+	return provider.OpenAPISchema()
+}
+
+// typeSchema returns the schema of a type which is not reflected, nil if the
+// type has to be reflected.
+func (r *reflector) typeSchema(_type reflect.Type) *openapi3.Schema {
+	if f := r.schemaFunc(_type); f != nil {
+		return f(_type)
+	}
+	return nil
+}
+
+// mergeSchemas returns a copy of base with the fields set in overlay.
+func mergeSchemas(base, overlay *openapi3.Schema) *openapi3.Schema {
+	ret := *base
+	dst := reflect.ValueOf(&ret).Elem()
+	src := reflect.ValueOf(overlay).Elem()
+	for i := range src.NumField() {
+		if field := src.Field(i); !field.IsZero() {
+			dst.Field(i).Set(field)
+		}
+	}
+	if base.Extensions != nil && overlay.Extensions != nil {
+		ret.Extensions = mergeExtensions(base.Extensions, overlay.Extensions)
+	}
+	return &ret
+}
+
+// mergeExtensions returns the extensions of base and overlay, the ones of
+// overlay take precedence.
+func mergeExtensions(base, overlay map[string]any) map[string]any {
+	ret := maps.Clone(base)
+	if ret == nil {
+		ret = make(map[string]any)
+	}
+	maps.Copy(ret, overlay)
+	return ret
+}
+
+// isRegistered reports whether the type of a schema is not reflected, it is
+// then described in place. Marshalers have their own json encoding and are
+// described as strings like their fields.
+func (r *reflector) isRegistered(s *Schema) bool {
+	_type := reflect.TypeOf(s.object)
+	for _type != nil && _type.Kind() == reflect.Pointer {
+		_type = _type.Elem()
+	}
+	return _type != nil && (r.schemaFunc(_type) != nil || isMarshaler(_type))
+}
@@ -0,0 +1,213 @@
+package openapigen
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fmarmol/kin-openapi/openapi3"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type Decimal struct {
+	value big.Int
+	exp   int32
+}
+
+type Invoice struct {
+	ID       uuid.UUID       `json:"id"`
+	Issued   time.Time       `json:"issued"`
+	Paid     *time.Time      `json:"paid,omitempty"`
+	Delay    time.Duration   `json:"delay"`
+	Link     url.URL         `json:"link"`
+	Server   netip.Addr      `json:"server"`
+	Raw      json.RawMessage `json:"raw"`
+	Pdf      []byte          `json:"pdf"`
+	Note     sql.NullString  `json:"note"`
+	Total    Decimal         `json:"total" oapi:"description:total amount"`
+	Lines    []Decimal       `json:"lines"`
+	Checksum [4]uint8        `json:"checksum"`
+}
+
+func TestRegisterType(t *testing.T) {
+
+	doc := &Document{}
+	doc.
+		RegisterType(reflect.TypeFor[Decimal](), func(reflect.Type) *openapi3.Schema {
+			return &openapi3.Schema{Type: &openapi3.Types{"string"}, Format: "decimal", Pattern: `^-?\d+(\.\d+)?$`}
+		}).
+		Paths(
+			NewPath("/invoices").Get().
+				Parameter(NewParameter("ids").InQuery().Ref([]uuid.UUID{})).
+				Responses(
+					NewResponse(200).
+						Header("Last-Modified", time.Time{}, "last invoice").
+						JSON(Invoice{}).Description("ok"),
+				),
+			NewPath("/now").Get().
+				Responses(NewResponse(200).JSON(time.Time{}).Description("ok")),
+		)
+
+	buffer := bytes.NewBuffer(nil)
+	err := doc.Write(buffer, 2)
+	fmt.Println(buffer.String())
+	require.NoError(t, err)
+	assert.Equal(t, testRegisterTypeExpectedSpecs, strings.ReplaceAll(buffer.String(), "\n", ""))
+
+	// registered types are checked against the validation keywords
+	err = (&Document{}).
+		RegisterType(reflect.TypeFor[Decimal](), func(reflect.Type) *openapi3.Schema {
+			return &openapi3.Schema{Type: &openapi3.Types{"string"}}
+		}).
+		Paths(NewPath("/invalid").Post().JSONBody(struct {
+			Amount Decimal `json:"amount" oapi:"min:0"`
+		}{})).
+		Build()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "field Amount: min:0 does not apply to a property of type string")
+}
+
+type Money struct {
+	Cents    int64
+	Currency string
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(fmt.Sprintf("%d.%02d %s", m.Cents/100, m.Cents%100, m.Currency))
+}
+
+func (Money) OpenAPISchema() *openapi3.Schema {
+	return &openapi3.Schema{Type: &openapi3.Types{"string"}, Pattern: `^\d+\.\d{2} [A-Z]{3}$`, Example: "12.50 EUR"}
+}
+
+type Color struct{ R, G, B uint8 }
+
+func (*Color) OpenAPISchema() *openapi3.Schema {
+	return &openapi3.Schema{Type: &openapi3.Types{"string"}, Format: "hex-color"}
+}
+
+type Product struct {
+	Price  Money   `json:"price"`
+	Colors []Color `json:"colors"`
+	Promo  *Money  `json:"promo,omitempty" oapi:"description:promotional price"`
+}
+
+func TestSchemaProvider(t *testing.T) {
+
+	doc := &Document{}
+	doc.
+		Paths(
+			NewPath("/products").Get().
+				Parameter(NewParameter("max_price").InQuery().Ref(Money{})).
+				Responses(NewResponse(200).JSON(Product{}).Description("ok")),
+		)
+
+	buffer := bytes.NewBuffer(nil)
+	err := doc.Write(buffer, 2)
+	fmt.Println(buffer.String())
+	require.NoError(t, err)
+	assert.Equal(t, testSchemaProviderExpectedSpecs, strings.ReplaceAll(buffer.String(), "\n", ""))
+
+	properties, _, err := Properties(Product{})
+	require.NoError(t, err)
+	require.Len(t, properties, 3)
+	assert.Equal(t, "string", properties[0]._type)
+}
+
+type Level int
+
+func (l Level) MarshalText() ([]byte, error) { return []byte(fmt.Sprintf("L%d", l)), nil }
+
+type Point struct{ X, Y float64 }
+
+func (p *Point) MarshalJSON() ([]byte, error) { return json.Marshal(fmt.Sprintf("%g,%g", p.X, p.Y)) }
+
+type Player struct {
+	Level     Level           `json:"level"`
+	Position  Point           `json:"position" oapi:"format:point"`
+	Scores    map[int]float64 `json:"scores"`
+	Unlocked  map[Level]bool  `json:"unlocked"`
+	Positions []*Point        `json:"positions"`
+}
+
+func TestMarshalers(t *testing.T) {
+
+	properties, _, err := Properties(Player{})
+	require.NoError(t, err)
+	require.Len(t, properties, 5)
+
+	schemas := make(map[string]*openapi3.Schema)
+	for _, property := range properties {
+		schemas[property.name] = oapiSchemaFromProperty(&property).Value
+	}
+	assert.Equal(t, &openapi3.Types{"string"}, schemas["level"].Type)
+	assert.Equal(t, &openapi3.Types{"string"}, schemas["position"].Type)
+	assert.Equal(t, "point", schemas["position"].Format)
+	assert.Equal(t, &openapi3.Types{"number"}, schemas["scores"].AdditionalProperties.Schema.Value.Type)
+	assert.Equal(t, &openapi3.Types{"boolean"}, schemas["unlocked"].AdditionalProperties.Schema.Value.Type)
+	assert.Equal(t, &openapi3.Types{"string"}, schemas["positions"].Items.Value.Type)
+
+	_, _, err = Properties(struct {
+		Grid map[Point]int
+	}{})
+	require.ErrorIs(t, err, ErrUnsupportedType)
+	assert.Contains(t, err.Error(), "map key has to be a string, an integer or an encoding.TextMarshaler, got openapigen.Point")
+}
+
+func TestMarshalerBodies(t *testing.T) {
+
+	doc := (&Document{}).Paths(
+		NewPath("/positions").Post().
+			JSONBody(Level(0)).
+			Responses(NewResponse(200).JSON(&Point{}).Description("ok")),
+	)
+	require.NoError(t, doc.Build())
+	assert.Empty(t, doc.t.Components.Schemas)
+
+	operation := doc.t.Paths.Value("/positions").Post
+	body := operation.RequestBody.Value.Content.Get("application/json").Schema
+	assert.Equal(t, "", body.Ref)
+	assert.Equal(t, &openapi3.Types{"string"}, body.Value.Type)
+	response := operation.Responses.Value("200").Value.Content.Get("application/json").Schema
+	assert.Equal(t, "", response.Ref)
+	assert.Equal(t, &openapi3.Types{"string"}, response.Value.Type)
+}
+
+type Blob []byte
+
+type Attachment struct {
+	Size     uint   `json:"size"`
+	Width    uint16 `json:"width"`
+	Checksum uint32 `json:"checksum"`
+	Data     Blob   `json:"data"`
+	Raw      Blob   `json:"raw" oapi:"format:binary"`
+}
+
+func TestKindTypes(t *testing.T) {
+
+	properties, _, err := Properties(Attachment{})
+	require.NoError(t, err)
+	require.Len(t, properties, 5)
+
+	schemas := make(map[string]*openapi3.Schema)
+	for _, property := range properties {
+		schemas[property.name] = oapiSchemaFromProperty(&property).Value
+	}
+	assert.Equal(t, &openapi3.Types{"integer"}, schemas["size"].Type)
+	assert.Equal(t, &openapi3.Types{"integer"}, schemas["width"].Type)
+	assert.Equal(t, &openapi3.Types{"integer"}, schemas["checksum"].Type)
+	assert.Equal(t, "uint32", schemas["checksum"].Format)
+	assert.Equal(t, &openapi3.Types{"string"}, schemas["data"].Type)
+	assert.Equal(t, "byte", schemas["data"].Format)
+	assert.Equal(t, "binary", schemas["raw"].Format)
+}
@@ -3,8 +3,6 @@ package openapigen
 import (
 	"reflect"
 	"strconv"
-
-	"github.com/google/uuid"
 )
 
 func isStruct(obj any) bool {
@@ -12,53 +10,11 @@ func isStruct(obj any) bool {
 
 }
 
-func isSlice(obj any) (reflect.Type, bool) {
-	_type := reflect.TypeOf(obj)
-	if _type.Kind() != reflect.Slice {
-		return nil, false
-	}
-	elem := _type.Elem()
-	return elem, true
-}
-
-func typeToProp(_type reflect.Type) (Property, bool) {
-	var property Property
-	switch {
-	case _type == reflect.TypeOf(uuid.UUID{}):
-		property._type = "string"
-		property.format = "uuid"
-		return property, false
-	}
-	kind := _type.Kind()
-
-	switch kind {
-	case reflect.Int8, reflect.Int16, reflect.Int:
-		property._type = "integer"
-	case reflect.Int32:
-		property._type = "integer"
-		property.format = "int32"
-	case reflect.Int64:
-		property._type = "integer"
-		property.format = "int64"
-	case reflect.Float64:
-		property._type = "number"
-		property.format = "double"
-	case reflect.Float32:
-		property._type = "number"
-		property.format = "float"
-	case reflect.Bool:
-		property._type = "boolean"
-	case reflect.String:
-		property._type = "string"
-	default:
-		return property, true
-	}
-	return property, false
-}
-
+// parseString converts the value of a tag to a boolean or a number when it
+// looks like one, "1" is a number and not a boolean.
 func parseString(value string) any {
-	if bool, err := strconv.ParseBool(value); err == nil {
-		return bool
+	if value == "true" || value == "false" {
+		return value == "true"
 	} else if val, err := strconv.ParseInt(value, 10, 64); err == nil {
 		return val
 	} else if val, err := strconv.ParseFloat(value, 64); err == nil {
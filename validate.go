@@ -0,0 +1,181 @@
+package openapigen
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ValidationTags reads the go-playground validator rules of the given struct
+// tags, like "validate" or "binding" for gin, to describe the constraints of
+// the fields. The oapi tag takes precedence over the rules.
+func (d *Document) ValidationTags(tags ...string) *Document {
+	d.validationTags = tags
+	return d
+}
+
+// validationFormats maps validator rules to the format of a string.
+var validationFormats = map[string]string{
+	"email":    "email",
+	"url":      "uri",
+	"uri":      "uri",
+	"uuid":     "uuid",
+	"uuid3":    "uuid",
+	"uuid4":    "uuid",
+	"uuid5":    "uuid",
+	"ipv4":     "ipv4",
+	"ipv6":     "ipv6",
+	"hostname": "hostname",
+}
+
+// validationRules returns the validator rules of a field, read from the tags
+// enabled on the document.
+func (r *reflector) validationRules(field reflect.StructField) []string {
+	var rules []string
+	for _, tag := range r.validation {
+		if value := field.Tag.Get(tag); value != "" && value != "-" {
+			rules = append(rules, strings.Split(value, ",")...)
+		}
+	}
+	return rules
+}
+
+// applyValidation translates validator rules into the constraints of a
+// property, the ones set by the oapi tag are kept. Rules following "dive"
+// apply to the items of a slice or the values of a map.
+func applyValidation(property *Property, rules []string, tag oapiTag) {
+	target := property
+	for _, rule := range rules {
+		name, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		switch name {
+		case "dive":
+			switch {
+			case target.itemsProp != nil:
+				target = target.itemsProp
+			case target.additionalProperties != nil:
+				target = target.additionalProperties
+			default:
+				return
+			}
+		case "required":
+			if target == property && !tag.has("required") {
+				property.required = true
+			}
+		case "min", "gte":
+			target.setLower(param, false)
+		case "gt":
+			target.setLower(param, true)
+		case "max", "lte":
+			target.setUpper(param, false)
+		case "lt":
+			target.setUpper(param, true)
+		case "len":
+			target.setLower(param, false)
+			target.setUpper(param, false)
+		case "oneof":
+			if target.enums == nil && target._const == nil {
+				for _, value := range strings.Fields(param) {
+					target.enums = append(target.enums, enumValue(value, target.jsonType()))
+				}
+			}
+		case "datetime":
+			if target._type == "string" && target.format == "" {
+				target.format = "date"
+				for _, clock := range []string{"15", "03", "04", "05"} { // hour, minute, second
+					if strings.Contains(param, clock) {
+						target.format = "date-time"
+					}
+				}
+			}
+		default:
+			if format, ok := validationFormats[name]; ok && target._type == "string" && target.format == "" {
+				target.format = format
+			}
+		}
+	}
+}
+
+// setLower sets the lower bound of a number, or the minimal length of a
+// string, a slice or a map.
+func (p *Property) setLower(param string, exclusive bool) {
+	value, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return
+	}
+	length := lengthLimit(value, exclusive, 1)
+	switch p.jsonType() {
+	case "integer", "number":
+		if p.minimum == nil {
+			p.minimum, p.exclusiveMinimum = &value, exclusive
+		}
+	case "string":
+		if p.minLength == nil {
+			p.minLength = length
+		}
+	case "array":
+		if p.minItems == nil {
+			p.minItems = length
+		}
+	case "object":
+		if p.minProperties == nil {
+			p.minProperties = length
+		}
+	}
+}
+
+// setUpper sets the upper bound of a number, or the maximal length of a
+// string, a slice or a map.
+func (p *Property) setUpper(param string, exclusive bool) {
+	value, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return
+	}
+	length := lengthLimit(value, exclusive, -1)
+	switch p.jsonType() {
+	case "integer", "number":
+		if p.maximum == nil {
+			p.maximum, p.exclusiveMaximum = &value, exclusive
+		}
+	case "string":
+		if p.maxLength == nil {
+			p.maxLength = length
+		}
+	case "array":
+		if p.maxItems == nil {
+			p.maxItems = length
+		}
+	case "object":
+		if p.maxProperties == nil {
+			p.maxProperties = length
+		}
+	}
+}
+
+// lengthLimit converts a bound to an inclusive length, step moves an exclusive
+// bound inside the range.
+func lengthLimit(value float64, exclusive bool, step int) *uint64 {
+	length := int(value)
+	if exclusive {
+		length += step
+	}
+	if length < 0 {
+		return nil
+	}
+	ret := uint64(length)
+	return &ret
+}
+
+// enumValue converts a value of a oneof rule to the type of the property.
+func enumValue(value string, _type string) any {
+	switch _type {
+	case "integer":
+		if val, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return val
+		}
+	case "number":
+		if val, err := strconv.ParseFloat(value, 64); err == nil {
+			return val
+		}
+	}
+	return value
+}
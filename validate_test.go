@@ -0,0 +1,47 @@
+package openapigen
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type CreateUser struct {
+	Name     string            `json:"name,omitempty" validate:"required,min=3,max=64"`
+	Email    string            `json:"email,omitempty" binding:"required,email"`
+	Role     string            `json:"role" validate:"oneof=admin user guest"`
+	Level    int               `json:"level" validate:"oneof=1 2 3"`
+	Age      int               `json:"age" validate:"gte=0,lt=150" oapi:"max:120"`
+	Website  string            `json:"website,omitempty" validate:"omitempty,url"`
+	Birthday string            `json:"birthday" validate:"datetime=2006-01-02"`
+	Team     string            `json:"team,omitempty" validate:"required,uuid4" oapi:"required:false"`
+	Tags     []string          `json:"tags" validate:"max=5,dive,min=2"`
+	Meta     map[string]string `json:"meta" validate:"gt=0"`
+}
+
+func TestValidationTags(t *testing.T) {
+
+	doc := (&Document{}).ValidationTags("validate", "binding")
+	doc.
+		Paths(
+			NewPath("/users").Post().
+				JSONBody(CreateUser{}).
+				Responses(NewResponse(204).Description("ok")),
+		)
+
+	buffer := bytes.NewBuffer(nil)
+	err := doc.Write(buffer, 2)
+	fmt.Println(buffer.String())
+	require.NoError(t, err)
+	assert.Equal(t, testValidationTagsExpectedSpecs, strings.ReplaceAll(buffer.String(), "\n", ""))
+
+	// the rules are ignored by default
+	properties, _, err := Properties(CreateUser{})
+	require.NoError(t, err)
+	assert.False(t, properties[0].required)
+	assert.Nil(t, properties[0].minLength)
+}
@@ -0,0 +1,241 @@
+package openapigen
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/fmarmol/kin-openapi/openapi3"
+)
+
+const (
+	OpenAPI30 = "3.0.0"
+	OpenAPI31 = "3.1.0"
+)
+
+type webhook struct {
+	name string
+	path *Path
+}
+
+// OpenAPIVersion sets the version of the generated document, 3.0.x (default)
+// or 3.1.x. The same declarations are translated to the keywords of each
+// version, like nullable which becomes a "null" type in 3.1.
+func (d *Document) OpenAPIVersion(version string) *Document {
+	d.openapiVersion = version
+	return d
+}
+
+// JSONSchemaDialect sets the default $schema of the schemas (3.1 only).
+func (d *Document) JSONSchemaDialect(uri string) *Document {
+	d.jsonSchemaDialect = uri
+	return d
+}
+
+// Webhook declares an operation initiated by the api (3.1 only), the path of
+// p is not used.
+func (d *Document) Webhook(name string, p *Path) *Document {
+	d.webhooks = append(d.webhooks, webhook{name: name, path: p})
+	return d
+}
+
+func (d *Document) version() string {
+	if d.openapiVersion == "" {
+		return OpenAPI30
+	}
+	return d.openapiVersion
+}
+
+func (d *Document) is31() bool {
+	return strings.HasPrefix(d.version(), "3.1.")
+}
+
+func (d *Document) checkVersion() error {
+	var errs []error
+	version := d.version()
+	if !strings.HasPrefix(version, "3.0.") && !strings.HasPrefix(version, "3.1.") {
+		errs = append(errs, fmt.Errorf("openapi version %s is not supported", version))
+	}
+	if !d.is31() {
+		if d.jsonSchemaDialect != "" {
+			errs = append(errs, fmt.Errorf("jsonSchemaDialect requires openapi 3.1, got %s", version))
+		}
+		if len(d.webhooks) > 0 {
+			errs = append(errs, fmt.Errorf("webhooks require openapi 3.1, got %s", version))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// buildWebhooks builds the operations of the webhooks, the same way the paths are.
+func (d *Document) buildWebhooks(refl *reflector) (map[string]*openapi3.PathItem, error) {
+	var errs []error
+	webhooks := make(map[string]*openapi3.PathItem)
+	for _, w := range d.webhooks {
+		operation, err := d.operation(refl, w.path)
+		errs = append(errs, splitErrors(err)...)
+		if webhooks[w.name] == nil {
+			webhooks[w.name] = new(openapi3.PathItem)
+		}
+		if err := setPathItemOperation(w.path.method, webhooks[w.name], operation); err != nil {
+			errs = append(errs, fmt.Errorf("webhook %s: %w", w.name, err))
+		}
+	}
+	return webhooks, errors.Join(errs...)
+}
+
+// upgradeSchemas translates the schemas of a built document from openapi 3.0
+// to 3.1 (JSON Schema 2020-12).
+func upgradeSchemas(t *openapi3.T, webhooks map[string]*openapi3.PathItem) {
+	visited := make(map[*openapi3.Schema]bool)
+	walk := func(ref *openapi3.SchemaRef) { walkSchemas(ref, visited, upgradeSchema) }
+
+	walkContent := func(content openapi3.Content) {
+		for _, mediaType := range content {
+			walk(mediaType.Schema)
+		}
+	}
+	walkResponse := func(response *openapi3.Response) {
+		if response == nil {
+			return
+		}
+		walkContent(response.Content)
+		for _, header := range response.Headers {
+			if header.Value != nil {
+				walk(header.Value.Schema)
+			}
+		}
+	}
+
+	for _, schema := range t.Components.Schemas {
+		walk(schema)
+	}
+	for _, param := range t.Components.Parameters {
+		if param.Value != nil {
+			walk(param.Value.Schema)
+		}
+	}
+	for _, response := range t.Components.Responses {
+		walkResponse(response.Value)
+	}
+	for _, body := range t.Components.RequestBodies {
+		if body.Value != nil {
+			walkContent(body.Value.Content)
+		}
+	}
+	for _, header := range t.Components.Headers {
+		if header.Value != nil {
+			walk(header.Value.Schema)
+		}
+	}
+	pathItems := slices.Collect(maps.Values(t.Paths.Map()))
+	pathItems = append(pathItems, slices.Collect(maps.Values(webhooks))...)
+	for _, pathItem := range pathItems {
+		for _, operation := range pathItem.Operations() {
+			for _, param := range operation.Parameters {
+				if param.Value != nil {
+					walk(param.Value.Schema)
+				}
+			}
+			if operation.RequestBody != nil && operation.RequestBody.Value != nil {
+				walkContent(operation.RequestBody.Value.Content)
+			}
+			for _, response := range operation.Responses.Map() {
+				walkResponse(response.Value)
+			}
+		}
+	}
+}
+
+func walkSchemas(ref *openapi3.SchemaRef, visited map[*openapi3.Schema]bool, f func(*openapi3.Schema)) {
+	if ref == nil || ref.Value == nil || visited[ref.Value] {
+		return
+	}
+	schema := ref.Value
+	visited[schema] = true
+	f(schema)
+
+	walkSchemas(schema.Items, visited, f)
+	walkSchemas(schema.Not, visited, f)
+	walkSchemas(schema.AdditionalProperties.Schema, visited, f)
+	for _, property := range schema.Properties {
+		walkSchemas(property, visited, f)
+	}
+	for _, refs := range []openapi3.SchemaRefs{schema.AllOf, schema.OneOf, schema.AnyOf} {
+		for _, ref := range refs {
+			walkSchemas(ref, visited, f)
+		}
+	}
+}
+
+func upgradeSchema(schema *openapi3.Schema) {
+	extensions := maps.Clone(schema.Extensions)
+	if extensions == nil {
+		extensions = make(map[string]any)
+	}
+
+	if schema.Nullable {
+		schema.Nullable = false
+		if schema.Type != nil && !slices.Contains(*schema.Type, openapi3.TypeNull) {
+			types := append(slices.Clone(*schema.Type), openapi3.TypeNull)
+			schema.Type = &types
+		}
+		if len(schema.Enum) > 0 && !slices.Contains(schema.Enum, nil) {
+			schema.Enum = append(slices.Clone(schema.Enum), nil)
+		}
+		// a nullable reference is either the referenced schema or null
+		if schema.Type == nil && len(schema.AllOf) == 1 {
+			schema.AnyOf = openapi3.SchemaRefs{schema.AllOf[0], openapi3.NewSchemaRef("", &openapi3.Schema{Type: &openapi3.Types{openapi3.TypeNull}})}
+			schema.AllOf = nil
+		}
+	}
+	if schema.ExclusiveMin && schema.Min != nil {
+		extensions["exclusiveMinimum"] = *schema.Min
+		schema.ExclusiveMin, schema.Min = false, nil
+	}
+	if schema.ExclusiveMax && schema.Max != nil {
+		extensions["exclusiveMaximum"] = *schema.Max
+		schema.ExclusiveMax, schema.Max = false, nil
+	}
+	if names, descriptions := extensions["x-enum-varnames"], extensions["x-enum-descriptions"]; len(schema.Enum) > 0 && (names != nil || descriptions != nil) {
+		schema.OneOf = enumOneOf(schema.Enum, names, descriptions)
+		schema.Enum = nil
+		delete(extensions, "x-enum-varnames")
+		delete(extensions, "x-enum-descriptions")
+	}
+	if len(schema.Enum) == 1 {
+		extensions["const"] = schema.Enum[0]
+		schema.Enum = nil
+	}
+	if schema.Example != nil {
+		extensions["examples"] = []any{schema.Example}
+		schema.Example = nil
+	}
+
+	if len(extensions) > 0 {
+		schema.Extensions = extensions
+	} else {
+		schema.Extensions = nil
+	}
+}
+
+// enumOneOf describes each value of an enum with a const schema, titled with
+// its name.
+func enumOneOf(values []any, names, descriptions any) openapi3.SchemaRefs {
+	nameList, _ := names.([]string)
+	descriptionList, _ := descriptions.([]string)
+	refs := make(openapi3.SchemaRefs, 0, len(values))
+	for i, value := range values {
+		schema := &openapi3.Schema{Extensions: map[string]any{"const": value}}
+		if i < len(nameList) {
+			schema.Title = nameList[i]
+		}
+		if i < len(descriptionList) {
+			schema.Description = descriptionList[i]
+		}
+		refs = append(refs, openapi3.NewSchemaRef("", schema))
+	}
+	return refs
+}
@@ -0,0 +1,83 @@
+package openapigen
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/fmarmol/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type Rating struct {
+	Kind   string   `json:"kind" oapi:"const:movie"`
+	Score  *float64 `json:"score" oapi:"min:0,max:5,exclusiveMaximum:true,nullable:true"`
+	Genre  MyEnum   `json:"genre"`
+	Review string   `json:"review,omitempty" oapi:"nullable:true"`
+}
+
+func TestOpenAPI31(t *testing.T) {
+
+	doc := &Document{Title: "ratings", Version: "1.0"}
+	doc.
+		OpenAPIVersion(OpenAPI31).
+		JSONSchemaDialect("https://spec.openapis.org/oas/3.1/dialect/base").
+		Webhook("newRating", NewPath("").Post().JSONBody(Rating{}).Responses(NewResponse(200).Description("ok"))).
+		Paths(
+			NewPath("/ratings").Get().
+				Responses(NewResponse(200).JSON([]Rating{}).Description("ok")),
+		)
+
+	buffer := bytes.NewBuffer(nil)
+	err := doc.Write(buffer, 2)
+	fmt.Println(buffer.String())
+	require.NoError(t, err)
+	assert.Equal(t, testOpenAPI31ExpectedSpecs, strings.ReplaceAll(buffer.String(), "\n", ""))
+}
+
+func TestOpenAPIVersionErrors(t *testing.T) {
+
+	doc := &Document{}
+	doc.
+		JSONSchemaDialect("https://spec.openapis.org/oas/3.1/dialect/base").
+		Webhook("newRating", NewPath("").Post())
+	err := doc.Build()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "jsonSchemaDialect requires openapi 3.1")
+	assert.Contains(t, err.Error(), "webhooks require openapi 3.1")
+
+	err = (&Document{}).OpenAPIVersion("2.0").Build()
+	require.EqualError(t, err, "openapi version 2.0 is not supported")
+}
+
+type Screening struct {
+	Movie *Movie `json:"movie" oapi:"nullable:true"`
+}
+
+func TestNullableRef(t *testing.T) {
+
+	newDoc := func() *Document {
+		return (&Document{}).Paths(
+			NewPath("/screenings").Get().Responses(NewResponse(200).JSON(Screening{}).Description("ok")),
+		)
+	}
+
+	doc := newDoc()
+	require.NoError(t, doc.Build())
+	movie := doc.t.Components.Schemas["Screening"].Value.Properties["movie"]
+	assert.Equal(t, "", movie.Ref)
+	assert.True(t, movie.Value.Nullable)
+	require.Len(t, movie.Value.AllOf, 1)
+	assert.Equal(t, "#/components/schemas/Movie", movie.Value.AllOf[0].Ref)
+
+	doc = newDoc().OpenAPIVersion(OpenAPI31)
+	require.NoError(t, doc.Build())
+	movie = doc.t.Components.Schemas["Screening"].Value.Properties["movie"]
+	assert.False(t, movie.Value.Nullable)
+	assert.Empty(t, movie.Value.AllOf)
+	require.Len(t, movie.Value.AnyOf, 2)
+	assert.Equal(t, "#/components/schemas/Movie", movie.Value.AnyOf[0].Ref)
+	assert.Equal(t, &openapi3.Types{openapi3.TypeNull}, movie.Value.AnyOf[1].Value.Type)
+}
@@ -1,30 +1,45 @@
 package openapigen
 
+import (
+	"errors"
+	"fmt"
+)
+
 type YamlDocument struct {
-	Openapi    any `yaml:"openapi,omitempty"`
-	Info       any `yaml:"info,omitempty"`
-	Servers    any `yaml:"servers,omitempty"`
-	Security   any `yaml:"security"`
-	Tags       any `yaml:"tags"`
-	Paths      any `yaml:"paths,omitempty"`
-	Components any `yaml:"components,omitempty"`
+	Openapi           any `yaml:"openapi,omitempty"`
+	Info              any `yaml:"info,omitempty"`
+	JSONSchemaDialect any `yaml:"jsonSchemaDialect,omitempty"`
+	Servers           any `yaml:"servers,omitempty"`
+	Security          any `yaml:"security,omitempty"`
+	Tags              any `yaml:"tags,omitempty"`
+	Paths             any `yaml:"paths,omitempty"`
+	Webhooks          any `yaml:"webhooks,omitempty"`
+	Components        any `yaml:"components,omitempty"`
 }
 
-func NewYamlDocument(d *Document) YamlDocument {
+func NewYamlDocument(d *Document) (YamlDocument, error) {
+	var ret YamlDocument
+	if d.t == nil {
+		return ret, errors.New("document is not built")
+	}
 
 	doc, err := d.t.MarshalYAML()
 	if err != nil {
-		panic(err)
+		return ret, err
+	}
+	dict, ok := doc.(map[string]any)
+	if !ok {
+		return ret, fmt.Errorf("unexpected yaml document type %T", doc)
 	}
-	dict := doc.(map[string]any)
 
-	var ret YamlDocument
 	ret.Openapi = dict["openapi"]
 	ret.Info = dict["info"]
+	ret.JSONSchemaDialect = dict["jsonSchemaDialect"]
 	ret.Servers = dict["servers"]
 	ret.Security = dict["security"]
 	ret.Tags = dict["tags"]
 	ret.Paths = dict["paths"]
+	ret.Webhooks = dict["webhooks"]
 	ret.Components = dict["components"]
-	return ret
+	return ret, nil
 }